@@ -2,20 +2,21 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"flag"
-	"log"
 	"strings"
 
 	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/otp/uri"
+	"github.com/ozgur-yalcin/mfa/src/config"
 	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
 	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type addCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
 	mode     string
@@ -23,6 +24,7 @@ type addCommand struct {
 	digits   int
 	period   int64
 	counter  int64
+	suite    string
 }
 
 func newAddCommand() *addCommand {
@@ -37,67 +39,95 @@ func (c *addCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *addCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *addCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]> <secret-key>\n[flags] <otpauth-uri>",
+		Short:   "add an account",
+		Example: "mfa account add GitHub:ozgur-yalcin ADOO3MCCCVO5AVD6\nmfa account add \"otpauth://totp/GitHub:ozgur-yalcin?secret=ADOO3MCCCVO5AVD6&issuer=GitHub\"",
+	}
+}
+
 func (c *addCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
-	c.fs.StringVar(&c.mode, "mode", "totp", "use time-variant TOTP mode or use event-based HOTP mode")
-	c.fs.StringVar(&c.mode, "m", "totp", "use time-variant TOTP mode or use event-based HOTP mode (shorthand)")
-	c.fs.StringVar(&c.hash, "hash", "SHA1", "A cryptographic hash method H")
-	c.fs.StringVar(&c.hash, "H", "SHA1", "A cryptographic hash method H (shorthand)")
-	c.fs.IntVar(&c.digits, "digits", 6, "A HOTP value digits d")
-	c.fs.IntVar(&c.digits, "l", 6, "A HOTP value digits d (shorthand)")
-	c.fs.Int64Var(&c.counter, "counter", 0, "used for HOTP, A counter C, which counts the number of iterations")
-	c.fs.Int64Var(&c.counter, "c", 0, "used for HOTP, A counter C, which counts the number of iterations (shorthand)")
-	c.fs.Int64Var(&c.period, "period", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
-	c.fs.Int64Var(&c.period, "i", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT (shorthand)")
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(2)
+	defaults := config.Current().OTP
+	c.fs.StringVar(&c.mode, "mode", "m", defaults.Mode, "use time-variant TOTP mode, event-based HOTP mode, Steam Guard mode or OCRA challenge-response mode")
+	c.fs.StringVar(&c.hash, "hash", "H", defaults.Hash, "A cryptographic hash method H")
+	c.fs.IntVar(&c.digits, "digits", "l", defaults.Digits, "A HOTP value digits d")
+	c.fs.Int64Var(&c.counter, "counter", "c", 0, "used for HOTP, A counter C, which counts the number of iterations")
+	c.fs.Int64Var(&c.period, "period", "i", defaults.Period, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
+	c.fs.StringVar(&c.suite, "suite", "", "", "used for OCRA, the RFC 6287 suite such as OCRA-1:HOTP-SHA1-6:QN08")
 }
 
 func (c *addCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
-	initialize.Init()
 	if err := c.fs.Parse(args); err != nil {
 		return err
 	}
-	var issuer, user, secret string
-	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
-		issuer = pairs[0]
-		user = pairs[1]
-		secret = c.fs.Arg(1)
+	if err := otp.ValidateDigits(c.digits); err != nil {
+		return invalidError(err)
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	account := &models.Account{
+		Mode:    c.mode,
+		Hash:    c.hash,
+		Digits:  c.digits,
+		Period:  c.period,
+		Counter: c.counter,
+		Suite:   c.suite,
+	}
+	if strings.HasPrefix(strings.ToLower(c.fs.Arg(0)), uri.Scheme+"://") {
+		key, err := uri.Parse(c.fs.Arg(0))
+		if err != nil {
+			return invalidError(err)
+		}
+		account.SetKey(key)
+	} else if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		account.Issuer = pairs[0]
+		account.User = pairs[1]
+		account.Secret = c.fs.Arg(1)
 	} else {
-		issuer = c.fs.Arg(0)
-		secret = c.fs.Arg(1)
+		account.Issuer = c.fs.Arg(0)
+		account.Secret = c.fs.Arg(1)
 	}
-	if issuer == "" {
-		return errors.New("issuer cannot be empty")
+	if account.Issuer == "" {
+		return usageError("issuer cannot be empty")
 	}
-	if secret == "" {
-		return errors.New("secret cannot be empty")
+	if account.Secret == "" {
+		return usageError("secret cannot be empty")
 	}
-	if _, err := c.generateCode(secret); err != nil {
-		return err
+	if err := otp.ValidateSecret(account.Secret); err != nil {
+		return invalidError(err)
+	}
+	if err := checkAccount(*account); err != nil {
+		return invalidError(err)
 	}
-	if err := c.addAccount(issuer, user, secret); err != nil {
+	if err := c.addAccount(account); err != nil {
 		return err
 	}
-	log.Println("account added successfully")
-	return
+	return report(cd, output.Result{
+		Message:  "account added successfully",
+		Accounts: []output.Account{accountOutput(*account)},
+	}, nil)
 }
 
-func (c *addCommand) generateCode(secret string) (code string, err error) {
-	if c.mode == "hotp" {
-		hotp := otp.NewHOTP(c.hash, c.digits, c.counter)
-		code, err = hotp.GeneratePassCode(secret)
-	} else if c.mode == "totp" {
-		totp := otp.NewTOTP(c.hash, c.digits, c.period)
-		code, err = totp.GeneratePassCode(secret)
-	} else {
-		return code, errors.New("mode should be hotp or totp")
-	}
-	if err != nil {
-		return code, err
+// checkAccount makes sure codes can be generated with the parameters of
+// account. An OCRA code answers a challenge, so only its suite is checked.
+func checkAccount(account models.Account) error {
+	if account.Mode == "ocra" {
+		_, err := otp.NewOCRA(account.Suite)
+		return err
 	}
-	return
+	_, err := account.OTP()
+	return err
 }
 
-func (c *addCommand) addAccount(issuer string, user string, secret string) (err error) {
+func (c *addCommand) addAccount(account *models.Account) (err error) {
 	db, err := database.LoadDatabase()
 	if err != nil {
 		return err
@@ -106,23 +136,13 @@ func (c *addCommand) addAccount(issuer string, user string, secret string) (err
 		return err
 	}
 	defer db.Close()
-	accounts, err := db.ListAccounts(issuer, user)
+	accounts, err := db.ListAccounts(account.Issuer, account.User)
 	if err != nil {
 		return err
 	}
 	if len(accounts) > 0 {
-		return errors.New("account already exists")
+		return errAccountExists
 	} else if len(accounts) == 0 {
-		account := &models.Account{
-			Issuer:  issuer,
-			User:    user,
-			Secret:  secret,
-			Mode:    c.mode,
-			Hash:    c.hash,
-			Digits:  c.digits,
-			Period:  c.period,
-			Counter: c.counter,
-		}
 		return db.AddAccount(account)
 	}
 	return
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
-	"flag"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/flags"
 )
 
 type Commander interface {
@@ -10,11 +12,34 @@ type Commander interface {
 	Run(ctx context.Context, cd *Ancestor, args []string) error
 	Commands() []Commander
 	Name() string
+	Help() Help
+}
+
+// Help describes a command for mfa help and -h.
+type Help struct {
+	// Usage is the command line after the command names, one line per form
+	// of the command.
+	Usage   string
+	Short   string
+	Long    string
+	Example string
+}
+
+// Flagger is implemented by commands that parse their own flags, so the flags
+// can be found by walking the command tree.
+type Flagger interface {
+	Flags() *flags.FlagSet
+}
+
+// PersistentFlagger is implemented by commands whose flags are also accepted
+// by all the commands below them, unless one has a flag of the same name.
+type PersistentFlagger interface {
+	PersistentFlags() *flags.FlagSet
 }
 
 type Ancestor struct {
 	Commander Commander
-	Command   *flag.FlagSet
+	Command   *flags.FlagSet
 	Root      *Ancestor
 	Parent    *Ancestor
 	ancestors []*Ancestor
@@ -28,13 +53,25 @@ func newExec() (*Exec, error) {
 	return New(&rootCommand{
 		name: "mfa",
 		commands: []Commander{
+			newAccountCommand(),
 			newGenCommand(),
+			newCopyCommand(),
+			newWatchCommand(),
+			newNextCommand(),
+			newVerifyCommand(),
 			newQrCommand(),
-			newAddCommand(),
-			newDelCommand(),
-			newSetCommand(),
-			newListCommand(),
+			newVaultCommand(),
 			newVersionCommand(),
+			newCompletionCommand(),
+			newHelpCommand(),
+			newCompleteCommand(),
+			newAliasCommand("add", "account", "add"),
+			newAliasCommand("new", "account", "new"),
+			newAliasCommand("set", "account", "set"),
+			newAliasCommand("del", "account", "del"),
+			newAliasCommand("list", "account", "list"),
+			newAliasCommand("show", "account", "show"),
+			newAliasCommand("export", "vault", "export"),
 		},
 	})
 }
@@ -52,8 +89,16 @@ func (c *Ancestor) init() (err error) {
 }
 
 func (c *Ancestor) run() (err error) {
-	c.Command = flag.NewFlagSet(c.Commander.Name(), flag.ContinueOnError)
+	c.Command = flags.New(c.Commander.Name())
 	c.Commander.Init(c)
+	if f, ok := c.Commander.(Flagger); ok && f.Flags() != nil {
+		// the closest parent wins when two define a flag of the same name
+		for cd := c.Parent; cd != nil; cd = cd.Parent {
+			if p, ok := cd.Commander.(PersistentFlagger); ok && p.PersistentFlags() != nil {
+				f.Flags().AddFlagSet(p.PersistentFlags())
+			}
+		}
+	}
 	for _, cc := range c.ancestors {
 		if err := cc.run(); err != nil {
 			return err
@@ -61,3 +106,33 @@ func (c *Ancestor) run() (err error) {
 	}
 	return
 }
+
+// path returns the command line leading to the command, such as "mfa qr export".
+func (c *Ancestor) path() string {
+	var names []string
+	for cd := c; cd != nil; cd = cd.Parent {
+		names = append([]string{cd.Commander.Name()}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
+// resolve returns the command at path below c, such as "account", "add".
+func (c *Ancestor) resolve(path []string) *Ancestor {
+	cd := c
+	for _, name := range path {
+		if cd = cd.child(name); cd == nil {
+			return nil
+		}
+	}
+	return cd
+}
+
+// child returns the subcommand called name, hidden ones included.
+func (c *Ancestor) child(name string) *Ancestor {
+	for _, cc := range c.ancestors {
+		if cc.Commander.Name() == name {
+			return cc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+)
+
+// accountCommands take an issuer or issuer:user as their first argument.
+var accountCommands = map[string]bool{
+	"del":    true,
+	"set":    true,
+	"rename": true,
+	"list":   true,
+	"copy":   true,
+	"watch":  true,
+	"show":   true,
+	"next":   true,
+	"verify": true,
+	"export": true,
+}
+
+// rootFlagValues and flagValues are completed after the flags with these
+// long names, of the root command and of the other commands.
+var rootFlagValues = map[string][]string{
+	"output": {"text", "json", "yaml", "csv", "raw"},
+}
+
+var flagValues = map[string][]string{
+	"match":     {"auto", "exact", "substring", "glob", "regex"},
+	"mode":      {"totp", "hotp", "steam", "ocra"},
+	"hash":      {"SHA1", "SHA256", "SHA512"},
+	"qr-format": {"auto", "blocks", "sixel", "kitty", "iterm"},
+	"format":    {"gauth-migration"},
+	"level":     {"L", "M", "Q", "H"},
+	"shape":     {"square", "rounded", "dot"},
+}
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+type completionCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+}
+
+func newCompletionCommand() *completionCommand {
+	return &completionCommand{name: "completion"}
+}
+
+func (c *completionCommand) Name() string {
+	return c.name
+}
+
+func (c *completionCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *completionCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *completionCommand) Help() Help {
+	return Help{
+		Usage:   "bash|zsh|fish",
+		Short:   "print a shell completion script",
+		Long:    "Print the completion script of a shell. Commands, flags, flag values and the\nnames of the accounts in the database are completed.",
+		Example: "source <(mfa completion bash)\nsource <(mfa completion zsh)\nmfa completion fish | source",
+	}
+}
+
+func (c *completionCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+}
+
+func (c *completionCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if c.fs.Arg(0) == "" {
+		return usageError("shell cannot be empty")
+	}
+	script, ok := completionScripts[c.fs.Arg(0)]
+	if !ok {
+		return usageError("shell should be bash, zsh or fish")
+	}
+	_, err = os.Stdout.WriteString(script)
+	return
+}
+
+// completeCommand is run by the completion scripts with the words of the
+// command line, the last one being completed, and prints the candidates one
+// per line. Nothing is printed when file names should be completed.
+type completeCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+}
+
+func newCompleteCommand() *completeCommand {
+	return &completeCommand{name: "__complete"}
+}
+
+func (c *completeCommand) Name() string {
+	return c.name
+}
+
+func (c *completeCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *completeCommand) Help() Help {
+	return Help{
+		Usage: "[word]...",
+		Short: "print the completions of a command line",
+	}
+}
+
+func (c *completeCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+}
+
+func (c *completeCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	// the words are not parsed, they are the flags of the command completed
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	for _, candidate := range complete(cd.Root, args[:len(args)-1], args[len(args)-1]) {
+		fmt.Println(candidate)
+	}
+	return
+}
+
+// hidden commands and deprecated aliases are left out of help and
+// completions.
+func hidden(cd *Ancestor) bool {
+	if _, ok := cd.Commander.(*aliasCommand); ok {
+		return true
+	}
+	return strings.HasPrefix(cd.Commander.Name(), "__")
+}
+
+// flagSet returns the flags of a command, the root command keeps its flags in
+// the Ancestor.
+func flagSet(cd *Ancestor) *flags.FlagSet {
+	if cd == cd.Root {
+		return cd.Command
+	}
+	if f, ok := cd.Commander.(Flagger); ok {
+		return f.Flags()
+	}
+	return nil
+}
+
+// takesValue reports whether word is a flag of cd that needs a value in the
+// next word.
+func takesValue(cd *Ancestor, word string) (name string, ok bool) {
+	name = strings.TrimLeft(word, "-")
+	if strings.Contains(name, "=") {
+		return name, false
+	}
+	fs := flagSet(cd)
+	if fs == nil {
+		return name, false
+	}
+	if !strings.HasPrefix(word, "--") {
+		// in a group such as -vm the first shorthand with a value takes the
+		// rest of the group, or the next word when it is the last one
+		for i, r := range name {
+			f := fs.Lookup(string(r))
+			if f == nil {
+				return name, false
+			}
+			if !f.IsBool() {
+				return f.Name, i+utf8.RuneLen(r) == len(name)
+			}
+		}
+		return name, false
+	}
+	f := fs.Lookup(name)
+	if f == nil || f.IsBool() {
+		return name, false
+	}
+	return f.Name, true
+}
+
+// complete walks the command tree along words and returns the candidates for
+// cur.
+func complete(root *Ancestor, words []string, cur string) (candidates []string) {
+	cd := root
+	var positional int
+	var pending string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if len(word) > 1 && strings.HasPrefix(word, "-") && word != "--" {
+			if name, ok := takesValue(cd, word); ok {
+				if i == len(words)-1 {
+					pending = name
+				}
+				i++
+			}
+			continue
+		}
+		if positional == 0 {
+			if sub := subcommand(cd, word); sub != nil {
+				cd = sub
+				continue
+			}
+		}
+		positional++
+	}
+	switch {
+	case pending != "":
+		candidates = values(cd, pending)
+	case strings.HasPrefix(cur, "-") && strings.Contains(cur, "="):
+		name, _, _ := strings.Cut(strings.TrimLeft(cur, "-"), "=")
+		prefix := cur[:strings.Index(cur, "=")+1]
+		for _, value := range values(cd, name) {
+			candidates = append(candidates, prefix+value)
+		}
+	case strings.HasPrefix(cur, "-"):
+		if fs := flagSet(cd); fs != nil {
+			fs.VisitAll(func(f *flags.Flag) {
+				candidates = append(candidates, "--"+f.Name)
+				if f.Short != "" {
+					candidates = append(candidates, "-"+f.Short)
+				}
+			})
+		}
+	case positional == 0:
+		for _, sub := range cd.ancestors {
+			if !hidden(sub) {
+				candidates = append(candidates, sub.Commander.Name())
+			}
+		}
+		if cd != root && accountCommands[cd.Commander.Name()] {
+			candidates = append(candidates, accountNames(cur)...)
+		}
+	}
+	var matched []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, cur) {
+			matched = append(matched, candidate)
+		}
+	}
+	return matched
+}
+
+func values(cd *Ancestor, name string) []string {
+	if cd == cd.Root {
+		return rootFlagValues[name]
+	}
+	if fs := flagSet(cd); fs != nil {
+		if f := fs.Lookup(name); f != nil && f.Inherited {
+			return rootFlagValues[name]
+		}
+	}
+	return flagValues[name]
+}
+
+// subcommand returns the subcommand called name, a deprecated alias is
+// completed as the command it stands for.
+func subcommand(cd *Ancestor, name string) *Ancestor {
+	for _, sub := range cd.ancestors {
+		if sub.Commander.Name() != name {
+			continue
+		}
+		if alias, ok := sub.Commander.(*aliasCommand); ok {
+			return cd.Root.resolve(alias.path)
+		}
+		if !hidden(sub) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// accountNames returns the issuers in the database and, once an issuer is
+// typed, its issuer:user names. Errors are ignored, completion just offers
+// nothing.
+func accountNames(cur string) (names []string) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return
+	}
+	if err := db.Open(); err != nil {
+		return
+	}
+	defer db.Close()
+	accounts, err := db.ListAccounts("", "")
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, account := range accounts {
+		for _, name := range []string{account.Issuer, accountName(account)} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+const bashCompletion = `# bash completion for mfa, load it with
+#   source <(mfa completion bash)
+
+_mfa() {
+    local line="${COMP_LINE:0:COMP_POINT}" words cur
+    read -ra words <<< "$line"
+    if [[ "$line" == *[[:space:]] ]]; then
+        words+=("")
+    fi
+    cur="${words[${#words[@]}-1]}"
+    local IFS=$'\n'
+    COMPREPLY=($(mfa __complete "${words[@]:1}" 2>/dev/null))
+    # bash splits words on colons, only the part after the last one is replaced
+    if [[ "$cur" == *:* && "$COMP_WORDBREAKS" == *:* ]]; then
+        local colon="${cur%"${cur##*:}"}" i
+        for i in "${!COMPREPLY[@]}"; do
+            COMPREPLY[i]="${COMPREPLY[i]#"$colon"}"
+        done
+    fi
+}
+
+complete -o default -F _mfa mfa
+`
+
+const zshCompletion = `#compdef mfa
+# zsh completion for mfa, load it with
+#   source <(mfa completion zsh)
+# or save it as _mfa in a directory of $fpath
+
+_mfa() {
+    local -a candidates
+    candidates=("${(@f)$(mfa __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+    if (( ${#candidates} == 0 )); then
+        _files
+        return
+    fi
+    compadd -- "${candidates[@]}"
+}
+
+if [[ "${funcstack[1]}" == "_mfa" ]]; then
+    _mfa "$@"
+else
+    compdef _mfa mfa
+fi
+`
+
+const fishCompletion = `# fish completion for mfa, load it with
+#   mfa completion fish | source
+
+function __mfa_complete
+    set -l args (commandline -opc)[2..-1] (commandline -ct)
+    set -l candidates (mfa __complete $args 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $candidates
+end
+
+complete -c mfa -f -a '(__mfa_complete)'
+`
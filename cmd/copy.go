@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ozgur-yalcin/mfa/src/clipboard"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type copyCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	copier   copier
+}
+
+func newCopyCommand() *copyCommand {
+	return &copyCommand{name: "copy"}
+}
+
+func (c *copyCommand) Name() string {
+	return c.name
+}
+
+func (c *copyCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *copyCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *copyCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]>",
+		Short:   "copy the code of an account to the clipboard",
+		Long:    "Copy the code of an account to the clipboard with the OSC 52 escape sequence,\nor a clipboard command, and clear it again after a while.",
+		Example: "mfa copy GitHub:ozgur-yalcin\nmfa copy --clipboard-command wl-copy --paste-command \"wl-paste -n\" GitHub",
+	}
+}
+
+func (c *copyCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.copier.flags(c.fs)
+}
+
+func (c *copyCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	account, code, err := c.copyCode(ctx, issuer, user)
+	if err != nil {
+		return err
+	}
+	item := accountOutput(account)
+	item.Code = code
+	if account.Mode == "totp" || account.Mode == "steam" {
+		item.Remaining = remaining(account.Period)
+	}
+	if err := report(cd, output.Result{
+		Message:  "code copied to clipboard",
+		Accounts: []output.Account{item},
+	}, nil); err != nil {
+		return err
+	}
+	return c.copier.clear(ctx, code)
+}
+
+func (c *copyCommand) copyCode(ctx context.Context, issuer string, user string) (account models.Account, code string, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return account, code, err
+	}
+	if err := db.Open(); err != nil {
+		return account, code, err
+	}
+	defer db.Close()
+	if account, err = findAccount(db, issuer, user); err != nil {
+		return account, code, err
+	}
+	switch account.Mode {
+	case "ocra":
+		return account, code, usageError("ocra accounts need a challenge, use account list --copy --challenge")
+	case "hotp":
+		if account, code, err = db.NextCode(account.ID); err != nil {
+			return account, code, err
+		}
+	default:
+		if err := c.copier.wait(ctx, account); err != nil {
+			return account, code, err
+		}
+		if code, err = account.OTP(); err != nil {
+			return account, code, err
+		}
+	}
+	return account, code, c.copier.copy(code)
+}
+
+// copier puts codes on the clipboard for copy and account list --copy and clears
+// them again after a while.
+type copier struct {
+	command   string
+	paste     string
+	timeout   time.Duration
+	remaining int64
+	clipboard clipboard.Clipboard
+}
+
+func (c *copier) flags(fs *flags.FlagSet) {
+	fs.StringVar(&c.command, "clipboard-command", "", "", "copy by running this command with the code on its input instead of using OSC 52, such as \"wl-copy\"")
+	fs.StringVar(&c.paste, "paste-command", "", "", "command printing the clipboard, it is then only cleared while it still holds the code")
+	fs.DurationVar(&c.timeout, "clear", "", 45*time.Second, "clear the clipboard after this long, 0 keeps the code")
+	fs.Int64Var(&c.remaining, "min-remaining", "", 5, "wait for the next TOTP code when fewer seconds than this remain")
+}
+
+// wait sleeps until the next period of a time based account when its code
+// is about to expire.
+func (c *copier) wait(ctx context.Context, account models.Account) error {
+	if account.Mode != "totp" && account.Mode != "steam" {
+		return nil
+	}
+	left := remaining(account.Period)
+	if left <= 0 || left >= c.remaining {
+		return nil
+	}
+	log.Printf("waiting %ds for the next code\n", left)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(left) * time.Second):
+		return nil
+	}
+}
+
+func (c *copier) copy(code string) (err error) {
+	if c.command != "" {
+		c.clipboard, err = clipboard.NewCommand(c.command, c.paste)
+	} else {
+		c.clipboard, err = clipboard.NewTerminal()
+	}
+	if err != nil {
+		return err
+	}
+	return c.clipboard.Copy(code)
+}
+
+// clear waits for the timeout and clears the clipboard, an interrupt clears
+// it right away.
+func (c *copier) clear(ctx context.Context, code string) error {
+	if c.clipboard == nil {
+		return nil
+	}
+	defer c.clipboard.Close()
+	if c.timeout <= 0 {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	log.Printf("clipboard is cleared in %s, press ctrl-c to clear it now\n", c.timeout)
+	select {
+	case <-ctx.Done():
+	case <-time.After(c.timeout):
+	}
+	return c.clipboard.Clear(code)
+}
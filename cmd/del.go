@@ -2,24 +2,28 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"flag"
-	"log"
 	"strings"
 
 	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type delCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
+	match    database.MatchMode
+	all      bool
 }
 
+// del matches exactly unless told otherwise, so an account that only
+// resembles the name given is never deleted.
 func newDelCommand() *delCommand {
-	return &delCommand{name: "del"}
+	return &delCommand{name: "del", match: database.MatchExact}
 }
 
 func (c *delCommand) Name() string {
@@ -30,15 +34,33 @@ func (c *delCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *delCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *delCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]>",
+		Short:   "delete accounts",
+		Long:    "Delete the accounts matching a search, which only matches whole names unless\n--match is given. Accounts named exactly as given are all deleted, when the\nsearch also matches other accounts mfa asks which one to delete unless --all is\ngiven.",
+		Example: "mfa account del GitHub:ozgur-yalcin\nmfa account del --all --match glob 'Git*'",
+	}
+}
+
 func (c *delCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.fs.Var(&c.match, "match", "", "how the issuer and user are matched (auto, exact, substring, glob, regex)")
+	c.fs.BoolVar(&c.all, "all", "a", false, "delete every matching account instead of asking which one")
 }
 
 func (c *delCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
-	initialize.Init()
 	if err := c.fs.Parse(args); err != nil {
 		return err
 	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
 	var issuer, user string
 	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
 		issuer = pairs[0]
@@ -47,32 +69,49 @@ func (c *delCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err
 		issuer = c.fs.Arg(0)
 	}
 	if issuer == "" {
-		return errors.New("issuer cannot be empty")
+		return usageError("issuer cannot be empty")
 	}
-	if err := c.delAccount(issuer, user); err != nil {
+	accounts, err := c.delAccount(issuer, user)
+	if err != nil {
 		return err
 	}
-	log.Println("accounts deleted successfully")
-	return
+	result := output.Result{Message: "accounts deleted successfully"}
+	for _, account := range accounts {
+		result.Accounts = append(result.Accounts, accountOutput(account))
+	}
+	return report(cd, result, nil)
 }
 
-func (c *delCommand) delAccount(issuer string, user string) (err error) {
+func (c *delCommand) delAccount(issuer string, user string) (accounts []models.Account, err error) {
 	db, err := database.LoadDatabase()
 	if err != nil {
-		return err
+		return accounts, err
 	}
 	if err := db.Open(); err != nil {
-		return err
+		return accounts, err
 	}
 	defer db.Close()
-	accounts, err := db.ListAccounts(issuer, user)
+	matches, err := searchAccounts(db, issuer, user, c.match)
 	if err != nil {
-		return err
+		return accounts, err
 	}
-	if len(accounts) == 0 {
-		return errors.New("account not found")
-	} else if len(accounts) > 0 {
-		return db.DelAccount(issuer, user)
+	if len(matches) == 0 {
+		return accounts, errAccountNotFound
+	}
+	// accounts named exactly as given are all deleted, partial matches
+	// only with --all or once the user picked one
+	exact := true
+	for _, match := range matches {
+		exact = exact && match.Exact
+	}
+	if c.all || exact {
+		accounts = matchedAccounts(matches)
+	} else {
+		account, err := selectExactAccount(query(issuer, user), matches)
+		if err != nil {
+			return accounts, err
+		}
+		accounts = []models.Account{account}
 	}
-	return
+	return accounts, db.DelAccounts(accounts)
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/ozgur-yalcin/mfa/otp/uri"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+var (
+	errAccountNotFound  = errors.New("account not found")
+	errMultipleAccounts = errors.New("multiple accounts found")
+	errPartialMatch     = errors.New("account only partly matches, give its whole name")
+	errAccountExists    = errors.New("account already exists")
+	errInvalidCode      = errors.New("code is invalid")
+)
+
+type classError struct {
+	class output.Class
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classError) Unwrap() error {
+	return e.err
+}
+
+// usageError reports missing or malformed arguments.
+func usageError(message string) error {
+	return &classError{class: output.ClassUsage, err: errors.New(message)}
+}
+
+// invalidError reports a secret, code parameter or URI that cannot be used.
+func invalidError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classError{class: output.ClassInvalidInput, err: err}
+}
+
+func classify(err error) output.Class {
+	var class *classError
+	var uriError *uri.Error
+	var flagError *flags.Error
+	switch {
+	case errors.As(err, &class):
+		return class.class
+	case errors.Is(err, flags.ErrHelp), errors.As(err, &flagError):
+		return output.ClassUsage
+	case errors.Is(err, errAccountNotFound):
+		return output.ClassNotFound
+	case errors.Is(err, errMultipleAccounts), errors.Is(err, errPartialMatch):
+		return output.ClassAmbiguous
+	case errors.Is(err, errAccountExists):
+		return output.ClassExists
+	case errors.Is(err, errInvalidCode):
+		return output.ClassInvalidCode
+	case errors.Is(err, database.ErrDatabase):
+		return output.ClassDatabase
+	case errors.As(err, &uriError):
+		return output.ClassInvalidInput
+	}
+	return output.ClassError
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/otp/migration"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+	"github.com/ozgur-yalcin/mfa/src/render"
+)
+
+type exportCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	format   string
+	batch    int
+	png      string
+	size     int
+	invert   bool
+	qrFormat string
+}
+
+func newExportCommand() *exportCommand {
+	return &exportCommand{name: "export"}
+}
+
+func (c *exportCommand) Name() string {
+	return c.name
+}
+
+func (c *exportCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *exportCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *exportCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] [issuer[:user]]",
+		Short:   "export accounts as QR codes",
+		Example: "mfa vault export --format gauth-migration\nmfa vault export --batch 5 --png export.png GitHub",
+	}
+}
+
+func (c *exportCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.fs.StringVar(&c.format, "format", "f", "gauth-migration", "export format, gauth-migration for Google Authenticator \"Transfer accounts\" QR codes")
+	c.fs.IntVar(&c.batch, "batch", "", 10, "maximum number of accounts in one QR code")
+	c.fs.StringVar(&c.png, "png", "", "", "write the QR codes to PNG files instead of the terminal, numbered when there is more than one")
+	c.fs.IntVar(&c.size, "size", "", 512, "width and height of the QR code images in pixels")
+	c.fs.BoolVar(&c.invert, "invert", "", false, "draw the QR codes for terminals with a light background")
+	c.fs.StringVar(&c.qrFormat, "qr-format", "", "auto", "how to draw the QR codes in the terminal (auto, blocks, sixel, kitty, iterm)")
+}
+
+func (c *exportCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	if c.format != "gauth-migration" {
+		return usageError("format should be gauth-migration")
+	}
+	if c.batch <= 0 {
+		return usageError("batch should be greater than zero")
+	}
+	if _, err := render.ParseFormat(c.qrFormat); err != nil {
+		return usageError(err.Error())
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	accounts, err := c.listAccounts(issuer, user)
+	if err != nil {
+		return err
+	}
+	var parameters []migration.Parameters
+	var result output.Result
+	for _, account := range accounts {
+		item := accountOutput(account)
+		p, err := c.parameters(account)
+		if err != nil {
+			log.Printf("%s %s skipped: %s\n", account.Issuer, account.User, err)
+			item.Error = err.Error()
+		} else {
+			parameters = append(parameters, p)
+		}
+		result.Accounts = append(result.Accounts, item)
+	}
+	if len(parameters) == 0 {
+		return errors.New("no accounts to export")
+	}
+	var id [4]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	payloads := migration.Batches(parameters, c.batch, int32(binary.BigEndian.Uint32(id[:])&0x7FFFFFFF))
+	if len(payloads) > migration.MaxBatchSize {
+		return usageError(fmt.Sprintf("more than %d QR codes, use a larger --batch", migration.MaxBatchSize))
+	}
+	for i, payload := range payloads {
+		result.URIs = append(result.URIs, payload.URI())
+		// structured output carries the URIs instead of drawing them
+		if c.png == "" && outputFormat(cd).Structured() {
+			continue
+		}
+		if err := c.writeQRCode(payload.URI(), i, len(payloads)); err != nil {
+			return err
+		}
+	}
+	result.Message = fmt.Sprintf("%d accounts exported in %d QR codes", len(parameters), len(payloads))
+	return report(cd, result, nil)
+}
+
+func (c *exportCommand) parameters(account models.Account) (parameters migration.Parameters, err error) {
+	key, err := account.Key()
+	if err != nil {
+		return parameters, err
+	}
+	return migration.NewParameters(key)
+}
+
+func (c *exportCommand) writeQRCode(contents string, index int, total int) (err error) {
+	writer := qrcode.NewQRCodeWriter()
+	if c.png == "" {
+		matrix, err := writer.Encode(contents, lib.BarcodeFormat_QR_CODE, 0, 0, nil)
+		if err != nil {
+			return err
+		}
+		log.Printf("QR code %d of %d\n", index+1, total)
+		format, _ := render.ParseFormat(c.qrFormat)
+		return render.Display(os.Stdout, matrix, format, c.invert)
+	}
+	matrix, err := writer.Encode(contents, lib.BarcodeFormat_QR_CODE, c.size, c.size, nil)
+	if err != nil {
+		return err
+	}
+	path := c.png
+	if total > 1 {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), index+1, ext)
+	}
+	return writeImage(path, matrix)
+}
+
+func (c *exportCommand) listAccounts(issuer string, user string) (accounts []models.Account, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return accounts, err
+	}
+	if err := db.Open(); err != nil {
+		return accounts, err
+	}
+	defer db.Close()
+	matches, err := searchAccounts(db, issuer, user, database.MatchAuto)
+	return matchedAccounts(matches), err
+}
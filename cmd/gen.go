@@ -2,16 +2,20 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"flag"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/src/config"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type genCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
 	mode     string
@@ -19,6 +23,9 @@ type genCommand struct {
 	digits   int
 	period   int64
 	counter  int64
+	suite    string
+	input    otp.OCRAInput
+	at       string
 }
 
 func newGenCommand() *genCommand {
@@ -33,45 +40,87 @@ func (c *genCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *genCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *genCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <secret-key>",
+		Short:   "generate a code without saving the secret key",
+		Long:    "Generate a code for a secret key without saving it. Secret keys are base32,\npadding, case, spaces and dashes do not matter, hex and base64 keys need a\nhex: or base64: prefix.",
+		Example: "mfa gen ADOO3MCCCVO5AVD6\nmfa gen -m hotp -c 1 ADOO3MCCCVO5AVD6\nmfa gen --at 2024-12-27T10:00:00Z ADOO3MCCCVO5AVD6\nmfa gen -m ocra --suite OCRA-1:HOTP-SHA1-6:QN08 --challenge 11111111 ADOO3MCCCVO5AVD6",
+	}
+}
+
 func (c *genCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
-	c.fs.StringVar(&c.mode, "mode", "totp", "use time-variant TOTP mode or use event-based HOTP mode")
-	c.fs.StringVar(&c.mode, "m", "totp", "use time-variant TOTP mode or use event-based HOTP mode (shorthand)")
-	c.fs.StringVar(&c.hash, "hash", "SHA1", "A cryptographic hash method H (SHA1, SHA256, SHA512)")
-	c.fs.StringVar(&c.hash, "H", "SHA1", "A cryptographic hash method H (SHA1, SHA256, SHA512) (shorthand)")
-	c.fs.IntVar(&c.digits, "digits", 6, "A HOTP value digits d")
-	c.fs.IntVar(&c.digits, "l", 6, "A HOTP value digits d (shorthand)")
-	c.fs.Int64Var(&c.counter, "counter", 0, "used for HOTP, A counter C, which counts the number of iterations")
-	c.fs.Int64Var(&c.counter, "c", 0, "used for HOTP, A counter C, which counts the number of iterations (shorthand)")
-	c.fs.Int64Var(&c.period, "period", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
-	c.fs.Int64Var(&c.period, "i", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT (shorthand)")
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	defaults := config.Current().OTP
+	c.fs.StringVar(&c.mode, "mode", "m", defaults.Mode, "use time-variant TOTP mode, event-based HOTP mode, Steam Guard mode or OCRA challenge-response mode")
+	c.fs.StringVar(&c.hash, "hash", "H", defaults.Hash, "A cryptographic hash method H (SHA1, SHA256, SHA512)")
+	c.fs.IntVar(&c.digits, "digits", "l", defaults.Digits, "A HOTP value digits d")
+	c.fs.Int64Var(&c.counter, "counter", "c", 0, "used for HOTP, A counter C, which counts the number of iterations")
+	c.fs.Int64Var(&c.period, "period", "i", defaults.Period, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
+	c.fs.StringVar(&c.suite, "suite", "", "", "used for OCRA, the RFC 6287 suite such as OCRA-1:HOTP-SHA1-6:QN08")
+	c.fs.StringVar(&c.input.Challenge, "challenge", "", "", "used for OCRA, the challenge question Q")
+	c.fs.StringVar(&c.input.PIN, "pin", "", "", "used for OCRA, the PIN P for suites with a PIN input")
+	c.fs.StringVar(&c.input.Session, "session", "", "", "used for OCRA, the hex encoded session information S for suites with a session input")
+	c.fs.StringVar(&c.at, "at", "", "", "used for TOTP, generate the code for the given time (RFC3339 or unix seconds) instead of now")
 }
 
 func (c *genCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
 	if err := c.fs.Parse(args); err != nil {
 		return err
 	}
+	if err := otp.ValidateDigits(c.digits); err != nil {
+		return invalidError(err)
+	}
 	secret := c.fs.Arg(0)
-	code, err := c.generateCode(secret)
+	if c.mode == "hotp" && c.fs.Changed("at") {
+		return usageError("--at cannot be used with hotp, whose codes depend on --counter")
+	}
+	at, err := c.parseTime(c.at)
 	if err != nil {
 		return err
 	}
-	log.Println("Code:", code)
-	return
+	code, err := c.generateCode(secret, at)
+	if err != nil {
+		return invalidError(err)
+	}
+	item := output.Account{Mode: c.mode, Code: code}
+	if (c.mode == "totp" || c.mode == "steam") && c.at == "" {
+		item.Remaining = remaining(c.period)
+	}
+	return report(cd, output.Result{Accounts: []output.Account{item}}, func() error {
+		log.Println("Code:", code)
+		return nil
+	})
 }
 
-func (c *genCommand) generateCode(secret string) (code string, err error) {
-	if c.mode == "hotp" {
-		hotp := otp.NewHOTP(c.hash, c.digits, c.counter)
-		code, err = hotp.GeneratePassCode(secret)
-	} else if c.mode == "totp" {
-		totp := otp.NewTOTP(c.hash, c.digits, c.period)
-		code, err = totp.GeneratePassCode(secret)
-	} else {
-		return code, errors.New("mode should be hotp or totp")
+func (c *genCommand) parseTime(value string) (at time.Time, err error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
 	}
+	at, err = time.Parse(time.RFC3339, value)
 	if err != nil {
-		return code, err
+		return at, usageError("time should be in RFC3339 format or unix seconds")
 	}
 	return
 }
+
+func (c *genCommand) generateCode(secret string, at time.Time) (code string, err error) {
+	account := models.Account{
+		Secret:  secret,
+		Mode:    c.mode,
+		Hash:    c.hash,
+		Digits:  c.digits,
+		Period:  c.period,
+		Counter: c.counter,
+		Suite:   c.suite,
+	}
+	return account.GenerateAt(at, c.input)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/flags"
+)
+
+// groupCommand only holds subcommands, run without one it prints its help.
+// When fallback is set, arguments that are not a subcommand are passed to the
+// fallback subcommand, as the group used to be that command.
+type groupCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	help     Help
+	fallback string
+}
+
+func newAccountCommand() *groupCommand {
+	return &groupCommand{
+		name: "account",
+		help: Help{
+			Usage:   "<command> [arguments]",
+			Short:   "add, change, remove and list accounts",
+			Example: "mfa account add GitHub:ozgur-yalcin ADOO3MCCCVO5AVD6\nmfa account list git\nmfa account rename GitHub:ozgur-yalcin GitHub:ozgur",
+		},
+		commands: []Commander{
+			newAddCommand(),
+			newNewCommand(),
+			newSetCommand(),
+			newRenameCommand(),
+			newDelCommand(),
+			newListCommand(),
+			newShowCommand(),
+		},
+	}
+}
+
+func newVaultCommand() *groupCommand {
+	return &groupCommand{
+		name: "vault",
+		help: Help{
+			Usage:   "<command> [arguments]",
+			Short:   "move accounts between mfa and other authenticators",
+			Example: "mfa vault export --png export.png\nmfa vault import 'otpauth-migration://offline?data=...'",
+		},
+		commands: []Commander{
+			newExportCommand(),
+			newImportCommand(),
+		},
+	}
+}
+
+func newQrCommand() *groupCommand {
+	return &groupCommand{
+		name: "qr",
+		help: Help{
+			Usage:   "<command> [arguments]",
+			Short:   "read and write QR codes",
+			Example: "mfa qr scan image.png\nmfa qr export GitHub:ozgur-yalcin",
+		},
+		commands: []Commander{
+			newQrScanCommand(),
+			newQrExportCommand(),
+		},
+		fallback: "scan",
+	}
+}
+
+func (c *groupCommand) Name() string {
+	return c.name
+}
+
+func (c *groupCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *groupCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *groupCommand) Help() Help {
+	return c.help
+}
+
+func (c *groupCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+}
+
+func (c *groupCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	err = c.fs.Parse(args)
+	if errors.Is(err, flags.ErrHelp) {
+		return err
+	}
+	if target := cd.child(c.fallback); target != nil && len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "%q without a command is deprecated, use %q instead\n", cd.path(), target.path())
+		return target.Commander.Run(ctx, target, args)
+	}
+	if err != nil {
+		return err
+	}
+	if c.fs.NArg() > 0 {
+		return unknownCommand(cd, c.fs.Arg(0))
+	}
+	return printHelp(os.Stdout, cd)
+}
+
+// aliasCommand keeps an old command line working, it is left out of help and
+// completions and runs the command at path with a deprecation warning.
+type aliasCommand struct {
+	name string
+	path []string
+}
+
+func newAliasCommand(name string, path ...string) *aliasCommand {
+	return &aliasCommand{name: name, path: path}
+}
+
+func (c *aliasCommand) Name() string {
+	return c.name
+}
+
+func (c *aliasCommand) Commands() []Commander {
+	return nil
+}
+
+func (c *aliasCommand) Help() Help {
+	return Help{Short: "deprecated, use " + strings.Join(c.path, " ")}
+}
+
+func (c *aliasCommand) Init(cd *Ancestor) {
+}
+
+func (c *aliasCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	target := cd.Root.resolve(c.path)
+	if target == nil {
+		return fmt.Errorf("command %v not found", c.path)
+	}
+	return target.Commander.Run(ctx, target, args)
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ozgur-yalcin/mfa/src/flags"
+)
+
+type helpCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+}
+
+func newHelpCommand() *helpCommand {
+	return &helpCommand{name: "help"}
+}
+
+func (c *helpCommand) Name() string {
+	return c.name
+}
+
+func (c *helpCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *helpCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *helpCommand) Help() Help {
+	return Help{
+		Usage:   "[command]...",
+		Short:   "show help for a command",
+		Example: "mfa help account list\nmfa help qr export",
+	}
+}
+
+func (c *helpCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+}
+
+func (c *helpCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	target := cd.Root
+	for _, name := range c.fs.Args() {
+		sub := target.child(name)
+		if sub != nil {
+			if alias, ok := sub.Commander.(*aliasCommand); ok {
+				sub = cd.Root.resolve(alias.path)
+			}
+		}
+		if sub == nil || hidden(sub) {
+			return unknownCommand(target, name)
+		}
+		target = sub
+	}
+	return printHelp(os.Stdout, target)
+}
+
+// printHelp writes the description, usage, subcommands, flags and examples
+// of a command.
+func printHelp(w io.Writer, cd *Ancestor) error {
+	help := cd.Commander.Help()
+	var b strings.Builder
+	if description := help.Long; description != "" {
+		b.WriteString(description + "\n\n")
+	} else if help.Short != "" {
+		b.WriteString(strings.ToUpper(help.Short[:1]) + help.Short[1:] + ".\n\n")
+	}
+	b.WriteString("Usage:\n")
+	for _, usage := range strings.Split(help.Usage, "\n") {
+		b.WriteString(strings.TrimRight("  "+cd.path()+" "+usage, " ") + "\n")
+	}
+	var commands []*Ancestor
+	for _, sub := range cd.ancestors {
+		if !hidden(sub) {
+			commands = append(commands, sub)
+		}
+	}
+	if len(commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		tw := tabwriter.NewWriter(&b, 0, 8, 3, ' ', 0)
+		for _, sub := range commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.Commander.Name(), sub.Commander.Help().Short)
+		}
+		tw.Flush()
+	}
+	// the root command lists all its flags, the others list the inherited
+	// ones as global flags
+	own := func(f *flags.Flag) bool { return !f.Inherited || cd == cd.Root }
+	if usages := flagUsages(flagSet(cd), own); usages != "" {
+		b.WriteString("\nFlags:\n" + usages)
+	}
+	inherited := func(f *flags.Flag) bool { return !own(f) }
+	if usages := flagUsages(flagSet(cd), inherited); usages != "" {
+		b.WriteString("\nGlobal flags:\n" + usages)
+	}
+	if help.Example != "" {
+		b.WriteString("\nExamples:\n")
+		for _, example := range strings.Split(help.Example, "\n") {
+			b.WriteString("  " + example + "\n")
+		}
+	}
+	if len(commands) > 0 {
+		fmt.Fprintf(&b, "\nRun \"%s <command>\" for more about a command.\n", helpCommandLine(cd))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// flagUsages lists the flags of fs accepted by filter with their shorthands.
+func flagUsages(fs *flags.FlagSet, filter func(*flags.Flag) bool) string {
+	if fs == nil {
+		return ""
+	}
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fs.VisitAll(func(f *flags.Flag) {
+		if !filter(f) {
+			return
+		}
+		kind, usage := flag.UnquoteUsage(&flag.Flag{Name: f.Name, Usage: f.Usage, Value: f.Value, DefValue: f.DefValue})
+		if kind == "value" {
+			kind = "string"
+		}
+		name := "    --" + f.Name
+		if f.Short != "" {
+			name = "-" + f.Short + ", --" + f.Name
+		}
+		if kind != "" {
+			name += " " + kind
+		}
+		switch f.DefValue {
+		case "", "0", "false", "0s":
+		default:
+			if kind == "string" {
+				usage += fmt.Sprintf(" (default %q)", f.DefValue)
+			} else {
+				usage += fmt.Sprintf(" (default %s)", f.DefValue)
+			}
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, usage)
+	})
+	tw.Flush()
+	return b.String()
+}
+
+// helpCommandLine returns the mfa help command line for a command.
+func helpCommandLine(cd *Ancestor) string {
+	root := cd.Root.Commander.Name()
+	if cd == cd.Root {
+		return root + " help"
+	}
+	return root + " help" + strings.TrimPrefix(cd.path(), root)
+}
+
+// unknownCommand reports a subcommand of cd that does not exist and suggests
+// the ones with a similar name.
+func unknownCommand(cd *Ancestor, name string) error {
+	message := fmt.Sprintf("unknown command %q for %q", name, cd.path())
+	suggestions := suggest(cd, name)
+	if len(suggestions) == 0 {
+		return usageError(fmt.Sprintf("%s, run \"%s\" for usage", message, helpCommandLine(cd)))
+	}
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = strconv.Quote(suggestion)
+	}
+	return usageError(fmt.Sprintf("%s, did you mean %s?", message, strings.Join(quoted, " or ")))
+}
+
+// suggest returns the visible subcommands of cd within two edits of name, or
+// starting with it or being the start of it, closest first. Deprecated names
+// are suggested as the command line replacing them.
+func suggest(cd *Ancestor, name string) (suggestions []string) {
+	name = strings.ToLower(name)
+	distances := make(map[string]int)
+	for _, sub := range cd.ancestors {
+		candidate := sub.Commander.Name()
+		suggestion := candidate
+		if alias, ok := sub.Commander.(*aliasCommand); ok {
+			suggestion = strings.Join(alias.path, " ")
+		} else if hidden(sub) {
+			continue
+		}
+		d := distance(name, candidate)
+		if len(name) > 1 && strings.HasPrefix(candidate, name) || len(candidate) > 2 && strings.HasPrefix(name, candidate) {
+			d = min(d, 1)
+		}
+		if _, seen := distances[suggestion]; d <= 2 && !seen {
+			distances[suggestion] = d
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return distances[suggestions[i]] < distances[suggestions[j]]
+	})
+	return
+}
+
+// distance is the number of inserted, deleted, replaced or swapped adjacent
+// characters between a and b.
+func distance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
@@ -2,29 +2,36 @@ package cmd
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"sort"
 	"strings"
 	"sync"
 	"text/tabwriter"
 
+	"github.com/ozgur-yalcin/mfa/otp"
 	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
 	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type listCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
+	hotp     bool
+	input    otp.OCRAInput
+	match    database.MatchMode
+	copy     bool
+	copier   copier
 }
 
 func newListCommand() *listCommand {
-	return &listCommand{name: "list"}
+	return &listCommand{name: "list", match: database.MatchAuto}
 }
 
 func (c *listCommand) Name() string {
@@ -35,15 +42,38 @@ func (c *listCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *listCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *listCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] [issuer[:user]]",
+		Short:   "list accounts and their codes",
+		Long:    "List the accounts matching a search and their codes. Searches ignore case and\nmatch part of the name, a search with *, ? or [ is a glob and a search between\nslashes is a regular expression.",
+		Example: "mfa account list\nmfa account list git\nmfa account list '/^git(hub|lab)$/:ozgur'\nmfa account list --copy GitHub:ozgur-yalcin",
+	}
+}
+
 func (c *listCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.fs.Var(&c.match, "match", "", "how the issuer and user are matched (auto, exact, substring, glob, regex)")
+	c.fs.BoolVar(&c.copy, "copy", "", false, "copy the code of the matching account to the clipboard")
+	c.copier.flags(c.fs)
+	c.fs.BoolVar(&c.hotp, "hotp", "", false, "also generate codes for HOTP accounts, which advances their counters")
+	c.fs.StringVar(&c.input.Challenge, "challenge", "", "", "answer this challenge question Q for OCRA accounts")
+	c.fs.StringVar(&c.input.PIN, "pin", "", "", "used for OCRA, the PIN P for suites with a PIN input")
+	c.fs.StringVar(&c.input.Session, "session", "", "", "used for OCRA, the hex encoded session information S for suites with a session input")
 }
 
 func (c *listCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
-	initialize.Init()
 	if err := c.fs.Parse(args); err != nil {
 		return err
 	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
 	var issuer, user string
 	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
 		issuer = pairs[0]
@@ -51,64 +81,152 @@ func (c *listCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err
 	} else {
 		issuer = c.fs.Arg(0)
 	}
-	if err := c.listAccounts(issuer, user); err != nil {
+	copied, err := c.listAccounts(ctx, cd, issuer, user)
+	if err != nil {
 		return err
 	}
-	return
+	return c.copier.clear(ctx, copied)
 }
 
-func (c *listCommand) listAccounts(issuer string, user string) (err error) {
+func (c *listCommand) listAccounts(ctx context.Context, cd *Ancestor, issuer string, user string) (copied string, err error) {
 	db, err := database.LoadDatabase()
 	if err != nil {
-		return err
+		return copied, err
 	}
 	if err := db.Open(); err != nil {
-		return err
+		return copied, err
 	}
 	defer db.Close()
-	accounts, err := db.ListAccounts(issuer, user)
+	matches, err := searchAccounts(db, issuer, user, c.match)
 	if err != nil {
-		return err
+		return copied, err
 	}
-	type otp struct {
-		issuer string
-		user   string
-		code   string
+	if len(matches) == 0 {
+		if c.copy {
+			return copied, errAccountNotFound
+		}
+		return copied, report(cd, output.Result{Message: "no accounts found!"}, nil)
 	}
-	var otps []otp
-	if len(accounts) == 0 {
-		log.Println("no accounts found!")
-	} else {
-		var wg sync.WaitGroup
-		for _, account := range accounts {
-			wg.Add(1)
-			go func(account models.Account) {
-				defer wg.Done()
-				code, err := account.OTP()
+	// the account to copy is chosen first, so all listed codes are generated
+	// after waiting for a fresh one
+	var chosen models.Account
+	if c.copy {
+		if chosen, err = selectAccount(query(issuer, user), matches); err != nil {
+			return copied, err
+		}
+		if err := c.copier.wait(ctx, chosen); err != nil {
+			return copied, err
+		}
+	}
+	// every account has its own slot, so the ranking of the search is kept
+	otps := make([]output.Account, len(matches))
+	failed := func(i int, item output.Account, err error) {
+		log.Printf("%s %s generate code error%s\n", item.Issuer, item.User, err)
+		item.Error = err.Error()
+		otps[i] = item
+	}
+	var wg sync.WaitGroup
+	for i, match := range matches {
+		account := match.Account
+		item := accountOutput(account)
+		if account.Mode == "hotp" {
+			if c.hotp {
+				updated, code, err := db.NextCode(account.ID)
 				if err != nil {
-					log.Printf("%s %s generate code error%s\n", account.Issuer, account.User, err)
-				} else {
-					otps = append(otps, otp{
-						issuer: account.Issuer,
-						user:   account.User,
-						code:   code,
-					})
+					failed(i, item, err)
+					continue
 				}
-			}(account)
+				item.Code = code
+				item.Counter = &updated.Counter
+			}
+			otps[i] = item
+			continue
 		}
-		wg.Wait()
-		sort.Slice(otps, func(i, j int) bool {
-			return otps[i].issuer < otps[j].issuer
-		})
+		if account.Mode == "ocra" {
+			if c.input.Challenge != "" {
+				code, err := c.respond(db, account)
+				if err != nil {
+					failed(i, item, err)
+					continue
+				}
+				item.Code = code
+			}
+			otps[i] = item
+			continue
+		}
+		wg.Add(1)
+		go func(i int, account models.Account) {
+			defer wg.Done()
+			code, err := account.OTP()
+			if err != nil {
+				failed(i, item, err)
+			} else {
+				item.Code = code
+				item.Remaining = remaining(account.Period)
+				otps[i] = item
+			}
+		}(i, account)
+	}
+	wg.Wait()
+	if c.copy {
+		if copied, err = c.copyCode(chosen, matches, otps); err != nil {
+			return copied, err
+		}
+	}
+	return copied, report(cd, output.Result{Accounts: otps}, func() error {
 		writer := tabwriter.NewWriter(os.Stdout, 8, 8, 1, '\t', 0)
 		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", "#", "Issuer", "User", "Code")
-		for i, item := range otps {
-			_, err := fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", i+1, item.issuer, item.user, item.code)
+		var i int
+		for _, item := range otps {
+			if item.Error != "" {
+				continue
+			}
+			code := item.Code
+			if code == "" {
+				code = "-"
+			}
+			i++
+			_, err := fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", i, item.Issuer, item.User, code)
 			if err != nil {
 				log.Printf(err.Error())
 			}
 		}
-		writer.Flush()
+		return writer.Flush()
+	})
+}
+
+func (c *listCommand) copyCode(chosen models.Account, matches []database.Match, otps []output.Account) (code string, err error) {
+	for i, match := range matches {
+		if match.Account.ID != chosen.ID {
+			continue
+		}
+		item := otps[i]
+		switch {
+		case item.Error != "":
+			return code, errors.New(item.Error)
+		case item.Code == "" && item.Mode == "hotp":
+			return code, usageError("use --hotp to copy the code of an HOTP account")
+		case item.Code == "" && item.Mode == "ocra":
+			return code, usageError("use --challenge to copy the code of an OCRA account")
+		}
+		if err := c.copier.copy(item.Code); err != nil {
+			return code, err
+		}
+		log.Printf("code of %s copied to clipboard\n", accountName(chosen))
+		return item.Code, nil
+	}
+	return code, errAccountNotFound
+}
+
+func (c *listCommand) respond(db *database.Database, account models.Account) (code string, err error) {
+	code, err = account.Respond(c.input)
+	if err != nil {
+		return code, err
+	}
+	if suite, err := otp.ParseOCRASuite(account.Suite); err == nil && suite.Counter {
+		if err := db.AdvanceCounter(account, account.Counter+1); err != nil {
+			return code, err
+		}
 	}
 	return
 }
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/src/config"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type newCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	mode     string
+	hash     string
+	digits   int
+	period   int64
+	counter  int64
+	bits     int
+	png      string
+	size     int
+}
+
+func newNewCommand() *newCommand {
+	return &newCommand{name: "new"}
+}
+
+func (c *newCommand) Name() string {
+	return c.name
+}
+
+func (c *newCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *newCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *newCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]>",
+		Short:   "add an account with a new random secret key",
+		Long:    "Add an account with a new random secret key and print its otpauth URI for\nenrolling a phone.",
+		Example: "mfa account new --png github.png GitHub:ozgur-yalcin",
+	}
+}
+
+func (c *newCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	defaults := config.Current().OTP
+	c.fs.StringVar(&c.mode, "mode", "m", defaults.Mode, "use time-variant TOTP mode, event-based HOTP mode or Steam Guard mode")
+	c.fs.StringVar(&c.hash, "hash", "H", defaults.Hash, "A cryptographic hash method H")
+	c.fs.IntVar(&c.digits, "digits", "l", defaults.Digits, "A HOTP value digits d")
+	c.fs.Int64Var(&c.counter, "counter", "c", 0, "used for HOTP, A counter C, which counts the number of iterations")
+	c.fs.Int64Var(&c.period, "period", "i", defaults.Period, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
+	c.fs.IntVar(&c.bits, "bits", "b", 160, "size of the generated secret in bits")
+	c.fs.StringVar(&c.png, "png", "", "", "also write the provisioning QR code to this PNG file")
+	c.fs.IntVar(&c.size, "size", "", 256, "width and height of the QR code image in pixels")
+}
+
+func (c *newCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := otp.ValidateDigits(c.digits); err != nil {
+		return invalidError(err)
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	if c.mode != "hotp" && c.mode != "totp" && c.mode != "steam" {
+		return usageError("mode should be hotp, totp or steam")
+	}
+	secret, err := otp.GenerateSecret(c.bits)
+	if err != nil {
+		return invalidError(err)
+	}
+	account := &models.Account{
+		Issuer:  issuer,
+		User:    user,
+		Secret:  secret,
+		Mode:    c.mode,
+		Hash:    c.hash,
+		Digits:  c.digits,
+		Period:  c.period,
+		Counter: c.counter,
+	}
+	if c.mode == "steam" {
+		account.Hash = "SHA1"
+		account.Digits = 5
+	}
+	if _, err := account.OTP(); err != nil {
+		return invalidError(err)
+	}
+	uri, err := account.URI()
+	if err != nil {
+		return err
+	}
+	if err := c.addAccount(account); err != nil {
+		return err
+	}
+	if c.png != "" {
+		if err := c.writeQRCode(uri, c.png); err != nil {
+			return err
+		}
+	}
+	item := accountOutput(*account)
+	item.Secret = secret
+	item.URI = uri
+	return report(cd, output.Result{
+		Message:  "account added successfully",
+		Accounts: []output.Account{item},
+	}, func() error {
+		log.Println("account added successfully")
+		log.Println("Secret:", secret)
+		log.Println("URI:", uri)
+		return nil
+	})
+}
+
+func (c *newCommand) writeQRCode(contents string, path string) (err error) {
+	writer := qrcode.NewQRCodeWriter()
+	matrix, err := writer.Encode(contents, lib.BarcodeFormat_QR_CODE, c.size, c.size, nil)
+	if err != nil {
+		return err
+	}
+	return writeImage(path, matrix)
+}
+
+func (c *newCommand) addAccount(account *models.Account) (err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	if err := db.Open(); err != nil {
+		return err
+	}
+	defer db.Close()
+	accounts, err := db.ListAccounts(account.Issuer, account.User)
+	if err != nil {
+		return err
+	}
+	if len(accounts) > 0 {
+		return errAccountExists
+	} else if len(accounts) == 0 {
+		return db.AddAccount(account)
+	}
+	return
+}
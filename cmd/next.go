@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type nextCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+}
+
+func newNextCommand() *nextCommand {
+	return &nextCommand{name: "next"}
+}
+
+func (c *nextCommand) Name() string {
+	return c.name
+}
+
+func (c *nextCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *nextCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *nextCommand) Help() Help {
+	return Help{
+		Usage:   "<issuer[:user]>",
+		Short:   "generate the code of an HOTP account and advance its counter",
+		Example: "mfa next GitHub:ozgur-yalcin",
+	}
+}
+
+func (c *nextCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+}
+
+func (c *nextCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	account, code, err := c.nextCode(issuer, user)
+	if err != nil {
+		return err
+	}
+	item := accountOutput(account)
+	item.Code = code
+	return report(cd, output.Result{Accounts: []output.Account{item}}, func() error {
+		log.Println("Code:", code)
+		return nil
+	})
+}
+
+func (c *nextCommand) nextCode(issuer string, user string) (account models.Account, code string, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return account, code, err
+	}
+	if err := db.Open(); err != nil {
+		return account, code, err
+	}
+	defer db.Close()
+	if account, err = findAccount(db, issuer, user); err != nil {
+		return account, code, err
+	}
+	return db.NextCode(account.ID)
+}
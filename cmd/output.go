@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+// outputFormat returns the format selected with the global --output flag.
+func outputFormat(cd *Ancestor) output.Format {
+	if cd == nil || cd.Root == nil {
+		return output.FormatText
+	}
+	if root, ok := cd.Root.Commander.(*rootCommand); ok && root.output != "" {
+		return root.output
+	}
+	return output.FormatText
+}
+
+// report writes the result of a command in the selected format. In the text
+// format text prints the human readable output, when it is nil the message
+// of the result is printed.
+func report(cd *Ancestor, result output.Result, text func() error) (err error) {
+	format := outputFormat(cd)
+	if format == output.FormatText && text != nil {
+		return text()
+	}
+	if format == output.FormatText {
+		log.Println(result.Message)
+		return
+	}
+	return output.Write(os.Stdout, format, result)
+}
+
+// reportFailure writes result like report but marks it as failed with err,
+// for commands that only partly succeeded.
+func reportFailure(cd *Ancestor, result output.Result, err error) error {
+	if !outputFormat(cd).Structured() {
+		if rerr := report(cd, result, nil); rerr != nil {
+			return rerr
+		}
+		return err
+	}
+	failure := output.Failure(classify(err), err)
+	result.Status = failure.Status
+	result.Error = failure.Error
+	if rerr := report(cd, result, nil); rerr != nil {
+		return rerr
+	}
+	return &reportedError{err}
+}
+
+// reportedError is returned when the failed result was already written, so
+// only the exit code is left to set.
+type reportedError struct {
+	error
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.error
+}
+
+// fail reports err in the selected format and returns the exit code of its
+// class. Raw output keeps stdout for codes, so errors go to stderr there.
+func fail(cd *Ancestor, err error) int {
+	class := classify(err)
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return class.ExitCode()
+	}
+	switch format := outputFormat(cd); format {
+	case output.FormatText:
+		log.Println(err)
+	case output.FormatRaw:
+		output.Write(os.Stderr, format, output.Failure(class, err))
+	default:
+		output.Write(os.Stdout, format, output.Failure(class, err))
+	}
+	return class.ExitCode()
+}
+
+func accountOutput(account models.Account) output.Account {
+	item := output.Account{
+		Issuer: account.Issuer,
+		User:   account.User,
+		Mode:   account.Mode,
+	}
+	switch account.Mode {
+	case "ocra":
+		item.Suite = account.Suite
+		item.Counter = &account.Counter
+	case "hotp":
+		item.Hash = account.Hash
+		item.Digits = account.Digits
+		item.Counter = &account.Counter
+	case "steam":
+		item.Period = account.Period
+	default:
+		item.Hash = account.Hash
+		item.Digits = account.Digits
+		item.Period = account.Period
+	}
+	return item
+}
+
+// remaining returns the seconds left until the code of a period changes.
+func remaining(period int64) int64 {
+	if period <= 0 {
+		return 0
+	}
+	return period - time.Now().Unix()%period
+}
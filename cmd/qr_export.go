@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode/decoder"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+	"github.com/ozgur-yalcin/mfa/src/render"
+)
+
+type qrExportCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	file     string
+	size     int
+	margin   int
+	level    string
+	color    string
+	bgcolor  string
+	shape    string
+	finder   string
+	logo     string
+	logoSize float64
+}
+
+func newQrExportCommand() *qrExportCommand {
+	return &qrExportCommand{name: "export"}
+}
+
+func (c *qrExportCommand) Name() string {
+	return c.name
+}
+
+func (c *qrExportCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *qrExportCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *qrExportCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]> -f <image-path>",
+		Short:   "write the QR code of an account to an image",
+		Long:    "Write the QR code of an account to an image, the format is chosen by the\nextension (.png, .jpg, .jpeg, .gif, .svg, .eps, .pdf).",
+		Example: "mfa qr export GitHub:ozgur-yalcin -f github.png --size 512 --level H\nmfa qr export GitHub:ozgur-yalcin -f github.pdf --color \"#003366\"",
+	}
+}
+
+func (c *qrExportCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.fs.StringVar(&c.file, "file", "f", "", "image file to write, the format is chosen by the extension (.png, .jpg, .jpeg, .gif, .svg, .eps, .pdf)")
+	c.fs.IntVar(&c.size, "size", "s", 256, "width and height of the image in pixels, or points for .eps and .pdf")
+	c.fs.IntVar(&c.margin, "margin", "", 4, "quiet zone around the QR code in modules")
+	c.fs.StringVar(&c.level, "level", "", "M", "error correction level (L, M, Q, H)")
+	c.fs.StringVar(&c.color, "color", "", "#000000", "color of the dark modules")
+	c.fs.StringVar(&c.bgcolor, "background", "", "#FFFFFF", "color of the light modules and the quiet zone")
+	c.fs.StringVar(&c.shape, "shape", "", "square", "shape of the data modules (square, rounded, dot)")
+	c.fs.StringVar(&c.finder, "finder-color", "", "", "color of the finder and alignment patterns, defaults to --color")
+	c.fs.StringVar(&c.logo, "logo", "", "", "image to place in the centre, forces error correction level H")
+	c.fs.Float64Var(&c.logoSize, "logo-size", "", render.DefaultLogoSize, "width of the logo relative to the QR code")
+}
+
+func (c *qrExportCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	if c.file == "" {
+		return usageError("file cannot be empty")
+	}
+	if c.margin < 0 {
+		return usageError("margin cannot be negative")
+	}
+	foreground, err := render.ParseColor(c.color)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	background, err := render.ParseColor(c.bgcolor)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	account, err := c.getAccount(issuer, user)
+	if err != nil {
+		return err
+	}
+	uri, err := account.URI()
+	if err != nil {
+		return err
+	}
+	hints := map[lib.EncodeHintType]interface{}{
+		lib.EncodeHintType_ERROR_CORRECTION: strings.ToUpper(c.level),
+		lib.EncodeHintType_MARGIN:           c.margin,
+	}
+	if c.styled() {
+		if isVector(c.file) {
+			return usageError("shapes, finder colors and logos are only supported for png, jpeg and gif")
+		}
+		style, err := c.style(foreground, background)
+		if err != nil {
+			return err
+		}
+		img, err := render.Styled(uri, style)
+		if err != nil {
+			return err
+		}
+		if err := writeImage(c.file, img); err != nil {
+			return err
+		}
+	} else if isVector(c.file) {
+		matrix, err := qrcode.NewQRCodeWriter().Encode(uri, lib.BarcodeFormat_QR_CODE, 0, 0, hints)
+		if err != nil {
+			return err
+		}
+		options := render.VectorOptions{
+			Size:       c.size,
+			Foreground: foreground,
+			Background: background,
+		}
+		if err := writeVector(c.file, matrix, options); err != nil {
+			return err
+		}
+	} else {
+		matrix, err := qrcode.NewQRCodeWriter().Encode(uri, lib.BarcodeFormat_QR_CODE, c.size, c.size, hints)
+		if err != nil {
+			return err
+		}
+		if err := writeImage(c.file, render.Colorize(matrix, foreground, background)); err != nil {
+			return err
+		}
+	}
+	return report(cd, output.Result{
+		Message:  "QR code written to " + c.file,
+		Accounts: []output.Account{accountOutput(account)},
+	}, nil)
+}
+
+func (c *qrExportCommand) styled() bool {
+	return c.shape != string(render.ShapeSquare) || c.finder != "" || c.logo != ""
+}
+
+func (c *qrExportCommand) style(foreground, background color.RGBA) (style render.Style, err error) {
+	level, err := decoder.ErrorCorrectionLevel_ValueOf(strings.ToUpper(c.level))
+	if err != nil {
+		return style, usageError("level should be L, M, Q or H")
+	}
+	shape, err := render.ParseShape(c.shape)
+	if err != nil {
+		return style, usageError(err.Error())
+	}
+	style = render.Style{
+		Level:      level,
+		Size:       c.size,
+		Margin:     c.margin,
+		Foreground: foreground,
+		Background: background,
+		Shape:      shape,
+		LogoSize:   c.logoSize,
+	}
+	if c.finder != "" {
+		if style.Finder, err = render.ParseColor(c.finder); err != nil {
+			return style, usageError(err.Error())
+		}
+		style.Alignment = style.Finder
+	}
+	if c.logo != "" {
+		if c.logoSize <= 0 || c.logoSize >= 1 {
+			return style, usageError("logo size should be between 0 and 1")
+		}
+		if style.Logo, err = readImage(c.logo); err != nil {
+			return style, err
+		}
+	}
+	return style, nil
+}
+
+func (c *qrExportCommand) getAccount(issuer string, user string) (account models.Account, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return account, err
+	}
+	if err := db.Open(); err != nil {
+		return account, err
+	}
+	defer db.Close()
+	return findAccount(db, issuer, user)
+}
+
+func writeImage(path string, img image.Image) (err error) {
+	var encode func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encode = func(w io.Writer) error { return png.Encode(w, img) }
+	case ".jpg", ".jpeg":
+		encode = func(w io.Writer) error { return jpeg.Encode(w, img, &jpeg.Options{Quality: 100}) }
+	case ".gif":
+		encode = func(w io.Writer) error { return gif.Encode(w, img, nil) }
+	default:
+		return errors.New("image format should be png, jpeg, gif, svg, eps or pdf")
+	}
+	return writeFile(path, encode)
+}
+
+// writeFile creates path and writes it with encode.
+func writeFile(path string, encode func(w io.Writer) error) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	// a failed close may lose the end of the file
+	return file.Close()
+}
+
+func readImage(path string) (img image.Image, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return img, err
+	}
+	defer file.Close()
+	img, _, err = image.Decode(file)
+	return img, err
+}
+
+func isVector(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg", ".eps", ".pdf":
+		return true
+	}
+	return false
+}
+
+func writeVector(path string, matrix *lib.BitMatrix, options render.VectorOptions) (err error) {
+	var encode func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		encode = func(w io.Writer) error { return render.SVG(w, matrix, options) }
+	case ".eps":
+		encode = func(w io.Writer) error { return render.EPS(w, matrix, options) }
+	case ".pdf":
+		encode = func(w io.Writer) error { return render.PDF(w, matrix, options) }
+	default:
+		return errors.New("vector format should be svg, eps or pdf")
+	}
+	return writeFile(path, encode)
+}
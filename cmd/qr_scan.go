@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"log"
+	"os"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/otp/migration"
+	"github.com/ozgur-yalcin/mfa/otp/uri"
+	"github.com/ozgur-yalcin/mfa/src/config"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type qrScanCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	mode     string
+	hash     string
+	digits   int
+	period   int64
+	counter  int64
+	// uris is set when the arguments are the URIs instead of images of them
+	uris bool
+}
+
+func newQrScanCommand() *qrScanCommand {
+	return &qrScanCommand{name: "scan"}
+}
+
+func newImportCommand() *qrScanCommand {
+	return &qrScanCommand{name: "import", uris: true}
+}
+
+func (c *qrScanCommand) Name() string {
+	return c.name
+}
+
+func (c *qrScanCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *qrScanCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *qrScanCommand) Help() Help {
+	if c.uris {
+		return Help{
+			Usage:   "[flags] <uri>...",
+			Short:   "add accounts from otpauth URIs",
+			Long:    "Add the accounts of otpauth URIs and Google Authenticator \"Transfer accounts\"\notpauth-migration URIs, such as the ones mfa -o json vault export prints.",
+			Example: "mfa vault import 'otpauth://totp/GitHub:ozgur-yalcin?secret=ADOO3MCCCVO5AVD6'\nmfa vault import 'otpauth-migration://offline?data=...'",
+		}
+	}
+	return Help{
+		Usage:   "[flags] <image-path>...",
+		Short:   "add accounts from QR code images",
+		Long:    "Add the accounts of otpauth QR codes and Google Authenticator \"Transfer\naccounts\" QR codes read from images.",
+		Example: "mfa qr scan image.png\nmfa qr scan export-1.png export-2.png",
+	}
+}
+
+func (c *qrScanCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	defaults := config.Current().OTP
+	c.fs.StringVar(&c.mode, "mode", "m", defaults.Mode, "use time-variant TOTP mode, event-based HOTP mode, Steam Guard mode or OCRA challenge-response mode")
+	c.fs.StringVar(&c.hash, "hash", "H", defaults.Hash, "A cryptographic hash method H")
+	c.fs.IntVar(&c.digits, "digits", "l", defaults.Digits, "A HOTP value digits d")
+	c.fs.Int64Var(&c.counter, "counter", "c", 0, "used for HOTP, A counter C, which counts the number of iterations")
+	c.fs.Int64Var(&c.period, "period", "i", defaults.Period, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
+}
+
+func (c *qrScanCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	if c.fs.NArg() == 0 && c.uris {
+		return usageError("uri cannot be empty")
+	} else if c.fs.NArg() == 0 {
+		return usageError("image path cannot be empty")
+	}
+	var accounts []*models.Account
+	batches := make(map[int32][]bool)
+	for _, arg := range c.fs.Args() {
+		content, err := c.content(arg)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(strings.ToLower(content), migration.Scheme+":") {
+			payload, err := migration.ParseURI(content)
+			if err != nil {
+				return invalidError(err)
+			}
+			for _, parameters := range payload.Parameters {
+				key, err := parameters.Key()
+				if err != nil {
+					log.Printf("%s %s skipped: %s\n", parameters.Issuer, parameters.Name, err)
+					continue
+				}
+				accounts = append(accounts, c.newAccount(key))
+			}
+			if payload.BatchSize > 1 {
+				if batches[payload.BatchID] == nil {
+					batches[payload.BatchID] = make([]bool, payload.BatchSize)
+				}
+				// codes of one batch id may disagree on the batch size
+				if batch := batches[payload.BatchID]; int(payload.BatchIndex) < len(batch) {
+					batch[payload.BatchIndex] = true
+				}
+			}
+			continue
+		}
+		key, err := uri.Parse(content)
+		if err != nil {
+			return invalidError(err)
+		}
+		accounts = append(accounts, c.newAccount(key))
+	}
+	for _, batch := range batches {
+		for i, seen := range batch {
+			if !seen {
+				log.Printf("migration QR code %d of %d is missing\n", i+1, len(batch))
+			}
+		}
+	}
+	for _, account := range accounts {
+		if err := otp.ValidateSecret(account.Secret); err != nil {
+			return invalidError(fmt.Errorf("%s %s: %w", account.Issuer, account.User, err))
+		}
+	}
+	if len(accounts) == 1 {
+		if err := c.addAccount(accounts[0]); err != nil {
+			return err
+		}
+		return report(cd, output.Result{
+			Message:  "account added successfully",
+			Accounts: []output.Account{accountOutput(*accounts[0])},
+		}, nil)
+	}
+	return c.addAccounts(cd, accounts)
+}
+
+func (c *qrScanCommand) newAccount(key *uri.Key) *models.Account {
+	account := &models.Account{
+		Mode:    c.mode,
+		Hash:    c.hash,
+		Digits:  c.digits,
+		Period:  c.period,
+		Counter: c.counter,
+	}
+	account.SetKey(key)
+	return account
+}
+
+// content returns the URI of an argument, read from the QR code in the image
+// unless the arguments are URIs.
+func (c *qrScanCommand) content(arg string) (string, error) {
+	if c.uris {
+		return arg, nil
+	}
+	qr, err := c.readQRCode(arg)
+	if err != nil {
+		return "", err
+	}
+	return html.UnescapeString(qr.String()), nil
+}
+
+func (c *qrScanCommand) readQRCode(path string) (*lib.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	source := lib.NewLuminanceSourceFromImage(img)
+	binary := lib.NewHybridBinarizer(source)
+	bitmap, err := lib.NewBinaryBitmap(binary)
+	if err != nil {
+		return nil, err
+	}
+	reader := qrcode.NewQRCodeReader()
+	return reader.Decode(bitmap, nil)
+}
+
+func (c *qrScanCommand) addAccount(account *models.Account) (err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	if err := db.Open(); err != nil {
+		return err
+	}
+	defer db.Close()
+	accounts, err := db.ListAccounts(account.Issuer, account.User)
+	if err != nil {
+		return err
+	}
+	if len(accounts) > 0 {
+		return errAccountExists
+	} else if len(accounts) == 0 {
+		return db.AddAccount(account)
+	}
+	return
+}
+
+func (c *qrScanCommand) addAccounts(cd *Ancestor, accounts []*models.Account) (err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	if err := db.Open(); err != nil {
+		return err
+	}
+	defer db.Close()
+	var added int
+	var result output.Result
+	for _, account := range accounts {
+		existing, err := db.ListAccounts(account.Issuer, account.User)
+		if err != nil {
+			return err
+		}
+		item := accountOutput(*account)
+		if len(existing) > 0 {
+			log.Printf("%s %s skipped: %s\n", account.Issuer, account.User, errAccountExists)
+			item.Error = errAccountExists.Error()
+			result.Accounts = append(result.Accounts, item)
+			continue
+		}
+		if err := db.AddAccount(account); err != nil {
+			return err
+		}
+		result.Accounts = append(result.Accounts, item)
+		added++
+	}
+	result.Message = fmt.Sprintf("%d of %d accounts added successfully", added, len(accounts))
+	if added < len(accounts) {
+		return reportFailure(cd, result, errors.New("some accounts were not added"))
+	}
+	return report(cd, result, nil)
+}
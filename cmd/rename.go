@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type renameCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	match    database.MatchMode
+}
+
+// rename matches exactly unless told otherwise, like set and del.
+func newRenameCommand() *renameCommand {
+	return &renameCommand{name: "rename", match: database.MatchExact}
+}
+
+func (c *renameCommand) Name() string {
+	return c.name
+}
+
+func (c *renameCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *renameCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *renameCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]> <new-issuer[:new-user]>",
+		Short:   "change the issuer and user of an account",
+		Long:    "Change the issuer and user of an account. Without a new user the user is kept,\nend the new name with a colon to remove it.",
+		Example: "mfa account rename GitHub:ozgur-yalcin GitHub:ozgur\nmfa account rename Github GitHub\nmfa account rename Steam:ozgur Steam:",
+	}
+}
+
+func (c *renameCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(2)
+	c.fs.Var(&c.match, "match", "", "how the issuer and user are matched (auto, exact, substring, glob, regex)")
+}
+
+func (c *renameCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	if c.fs.Arg(1) == "" {
+		return usageError("new name cannot be empty")
+	}
+	account, err := c.renameAccount(issuer, user, c.fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	return report(cd, output.Result{
+		Message:  "account renamed successfully",
+		Accounts: []output.Account{accountOutput(account)},
+	}, nil)
+}
+
+func (c *renameCommand) renameAccount(issuer string, user string, name string) (account models.Account, err error) {
+	newIssuer, newUser, ok := strings.Cut(name, ":")
+	if newIssuer == "" {
+		return account, usageError("new issuer cannot be empty")
+	}
+	rename := func(account models.Account) models.Account {
+		account.Issuer = newIssuer
+		if ok {
+			account.User = newUser
+		}
+		return account
+	}
+	_, account, err = changeAccount(issuer, user, c.match, rename, nil, false)
+	return account, err
+}
+
+// checkName fails with errAccountExists when an account other than account
+// is already called issuer and user.
+func checkName(db *database.Database, account models.Account, issuer string, user string) error {
+	existing, err := db.ListAccounts(issuer, user)
+	if err != nil {
+		return err
+	}
+	// an empty user matches every user of the issuer in ListAccounts
+	for _, other := range existing {
+		if other.ID != account.ID && other.User == user {
+			return errAccountExists
+		}
+	}
+	return nil
+}
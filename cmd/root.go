@@ -2,18 +2,73 @@ package cmd
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
+	"log"
 	"log/slog"
 	"os"
 
+	"github.com/ozgur-yalcin/mfa/src/config"
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type rootCommand struct {
-	fs       *flag.FlagSet
-	commands []Commander
-	name     string
+	fs         *flags.FlagSet
+	persistent *flags.FlagSet
+	commands   []Commander
+	name       string
+	output     output.Format
+	config     string
+	db         string
+	loaded     bool
+}
+
+// formatFlag sets the output format. Logging moves to stderr as soon as a
+// structured format is chosen, so it works after the command too.
+type formatFlag struct {
+	format *output.Format
+}
+
+func (f formatFlag) String() string {
+	if f.format == nil {
+		return ""
+	}
+	return f.format.String()
+}
+
+func (f formatFlag) Set(value string) error {
+	if err := f.format.Set(value); err != nil {
+		return err
+	}
+	if f.format.Structured() {
+		// keep stdout for the structured result, progress and warnings
+		// are logged to stderr
+		log.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// dbFlag sets the database, after the command it changes the configuration
+// already loaded.
+type dbFlag struct {
+	r *rootCommand
+}
+
+func (f dbFlag) String() string {
+	if f.r == nil {
+		return ""
+	}
+	return f.r.db
+}
+
+func (f dbFlag) Set(value string) error {
+	f.r.db = value
+	if f.r.loaded {
+		return config.Current().SetDB(value)
+	}
+	return nil
 }
 
 func (r *rootCommand) Name() string {
@@ -21,7 +76,53 @@ func (r *rootCommand) Name() string {
 }
 
 func (r *rootCommand) Init(cd *Ancestor) {
-	r.fs = flag.NewFlagSet(r.name, flag.ExitOnError)
+	r.fs = cd.Command
+	r.output = output.FormatText
+	// global flags come before the command, the persistent ones may also
+	// follow it unless the command has a flag of the same name
+	r.fs.SetInterspersed(false)
+	r.persistent = flags.New(r.name)
+	r.persistent.Var(formatFlag{&r.output}, "output", "o", "output format (text, json, yaml, csv, raw)")
+	r.persistent.Var(dbFlag{r}, "db", "", "SQLite database file or postgres:// URL, overrides the configuration")
+	r.fs.StringVar(&r.config, "config", "", os.Getenv("MFA_CONFIG"), "configuration file (default $XDG_CONFIG_HOME/mfa/config)")
+	r.fs.AddFlagSet(r.persistent)
+}
+
+func (r *rootCommand) PersistentFlags() *flags.FlagSet {
+	return r.persistent
+}
+
+// loadConfig reads the configuration file, then the MFA_* environment
+// variables and the --db flag, each overriding the one before.
+func (r *rootCommand) loadConfig() (err error) {
+	path, required := r.config, r.config != ""
+	if path == "" {
+		if path, err = config.Path(); err != nil {
+			return err
+		}
+	}
+	c, err := config.Load(path, required)
+	if err != nil {
+		return err
+	}
+	if err := c.Env(os.LookupEnv); err != nil {
+		return err
+	}
+	if r.db != "" {
+		if err := c.SetDB(r.db); err != nil {
+			return err
+		}
+	}
+	legacy, err := c.MigrateLegacy()
+	if err != nil {
+		return err
+	}
+	if legacy != "" {
+		fmt.Fprintf(os.Stderr, "copied %s of an earlier version to %s, remove it once the accounts are there\n", legacy, config.DataPath())
+	}
+	config.Use(c)
+	r.loaded = true
+	return
 }
 
 func (r *rootCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
@@ -33,37 +134,88 @@ func (r *rootCommand) Commands() []Commander {
 	return r.commands
 }
 
+func (r *rootCommand) Help() Help {
+	return Help{
+		Usage: "[flags] <command> [arguments]",
+		Short: "generate and validate one-time passwords",
+		Long:  "mfa generates and validates one-time passwords, TOTP, HOTP, Steam Guard\nand OCRA codes, and keeps the secret keys of your accounts in a database.",
+	}
+}
+
 func (r *Exec) Execute(ctx context.Context, args []string) (*Ancestor, error) {
 	if err := r.c.init(); err != nil {
 		return nil, err
 	}
+	if err := r.c.Command.Parse(args); err != nil {
+		return r.c, flagError(r.c, err)
+	}
+	args = r.c.Command.Args()
+	if root, ok := r.c.Commander.(*rootCommand); ok {
+		if err := root.loadConfig(); err != nil {
+			return r.c, invalidError(err)
+		}
+	}
+	// the commands are set up once the configuration is loaded, as their
+	// flag defaults come from it
+	for _, cc := range r.c.ancestors {
+		if err := cc.run(); err != nil {
+			return r.c, err
+		}
+	}
 	cd := r.c
-	if len(args) > 0 {
-		for _, subcmd := range r.c.ancestors {
-			if subcmd.Commander.Name() == args[0] {
-				cd = subcmd
-				break
-			}
+	for len(args) > 0 {
+		sub := cd.child(args[0])
+		if sub == nil {
+			break
 		}
+		cd, args = sub, args[1:]
 	}
-	if err := cd.Command.Parse(args); err != nil {
-		return cd, err
+	if alias, ok := cd.Commander.(*aliasCommand); ok {
+		if target := cd.Root.resolve(alias.path); target != nil {
+			fmt.Fprintf(os.Stderr, "%q is deprecated, use %q instead\n", cd.path(), target.path())
+			cd = target
+		}
 	}
-	if err := cd.Commander.Run(ctx, cd, cd.Command.Args()[1:]); err != nil {
-		return cd, err
+	if cd == r.c {
+		if len(args) > 0 {
+			return cd, unknownCommand(cd, args[0])
+		}
+		if err := cd.Commander.Run(ctx, cd, args); err != nil {
+			return cd, err
+		}
+		return cd, printHelp(os.Stdout, cd)
+	}
+	if err := cd.Commander.Run(ctx, cd, args); err != nil {
+		return cd, flagError(cd, err)
 	}
 	return cd, nil
 }
 
-func Execute(args []string) (err error) {
+// flagError prints the help of the command when it was asked for and turns
+// flag errors into usage errors, other errors are returned as they are.
+func flagError(cd *Ancestor, err error) error {
+	var parse *flags.Error
+	switch {
+	case errors.Is(err, flags.ErrHelp):
+		return printHelp(os.Stdout, cd)
+	case errors.As(err, &parse):
+		return &classError{class: output.ClassUsage, err: fmt.Errorf("%w, run \"%s\" for usage", err, helpCommandLine(cd))}
+	}
+	return err
+}
+
+// Execute runs the command line and returns the exit code, errors are
+// reported in the selected output format.
+func Execute(args []string) int {
 	x, err := newExec()
 	if err != nil {
-		return err
+		log.Println(err)
+		return 1
 	}
-	if _, err := x.Execute(context.Background(), args); err != nil {
-		return err
+	if cd, err := x.Execute(context.Background(), args); err != nil {
+		return fail(cd, err)
 	}
-	return err
+	return 0
 }
 
 func New(rootCmd Commander) (*Exec, error) {
@@ -86,8 +238,7 @@ func New(rootCmd Commander) (*Exec, error) {
 	for _, c := range rootCmd.Commands() {
 		addCommands(root, c)
 	}
-	if err := root.run(); err != nil {
-		return nil, err
-	}
+	root.Command = flags.New(rootCmd.Name())
+	rootCmd.Init(root)
 	return &Exec{c: root}, nil
 }
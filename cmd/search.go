@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/tui"
+)
+
+// maxChoices limits how many accounts are offered when asking the user.
+const maxChoices = 20
+
+// searchAccounts finds the accounts matching issuer and user, best first.
+func searchAccounts(db *database.Database, issuer string, user string, mode database.MatchMode) ([]database.Match, error) {
+	matches, err := db.SearchAccounts(issuer, user, mode)
+	if err != nil && !errors.Is(err, database.ErrDatabase) {
+		return nil, invalidError(err)
+	}
+	return matches, err
+}
+
+// findAccount returns the one account issuer and user point to.
+func findAccount(db *database.Database, issuer string, user string) (account models.Account, err error) {
+	matches, err := searchAccounts(db, issuer, user, database.MatchAuto)
+	if err != nil {
+		return account, err
+	}
+	return selectAccount(query(issuer, user), matches)
+}
+
+func matchedAccounts(matches []database.Match) []models.Account {
+	accounts := make([]models.Account, len(matches))
+	for i, match := range matches {
+		accounts[i] = match.Account
+	}
+	return accounts
+}
+
+// selectAccount narrows matches down to one account. A single exact match
+// wins over partial ones, otherwise the user is asked to choose when mfa runs
+// in a terminal.
+func selectAccount(query string, matches []database.Match) (account models.Account, err error) {
+	if len(matches) == 0 {
+		return account, errAccountNotFound
+	} else if len(matches) == 1 {
+		return matches[0].Account, nil
+	}
+	var exact []database.Match
+	for _, match := range matches {
+		if match.Exact {
+			exact = append(exact, match)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0].Account, nil
+	}
+	if !tui.IsTerminal(int(os.Stdin.Fd())) || !tui.IsTerminal(int(os.Stderr.Fd())) {
+		return account, errMultipleAccounts
+	}
+	return chooseAccount(query, matches)
+}
+
+// selectExactAccount is selectAccount for the commands that change or delete
+// an account, a single partial match is only used once the user picked it in
+// a terminal.
+func selectExactAccount(query string, matches []database.Match) (account models.Account, err error) {
+	if len(matches) != 1 || matches[0].Exact {
+		return selectAccount(query, matches)
+	}
+	if !tui.IsTerminal(int(os.Stdin.Fd())) || !tui.IsTerminal(int(os.Stderr.Fd())) {
+		return account, errPartialMatch
+	}
+	return chooseAccount(query, matches)
+}
+
+// chooseAccount lists the matches on stderr and reads the number of the
+// account to use from stdin.
+func chooseAccount(query string, matches []database.Match) (account models.Account, err error) {
+	shown := min(len(matches), maxChoices)
+	fmt.Fprintf(os.Stderr, "%d accounts match %q:\n", len(matches), query)
+	for i, match := range matches[:shown] {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, accountName(match.Account))
+	}
+	if len(matches) > shown {
+		fmt.Fprintf(os.Stderr, "  and %d more, narrow the search to see them\n", len(matches)-shown)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stderr, "select an account [1-%d]: ", shown)
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" && err != nil {
+			return account, errMultipleAccounts
+		}
+		if line == "" {
+			return account, usageError("no account selected")
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= shown {
+			return matches[n-1].Account, nil
+		}
+		fmt.Fprintf(os.Stderr, "selection should be a number between 1 and %d\n", shown)
+	}
+}
+
+func accountName(account models.Account) string {
+	return query(account.Issuer, account.User)
+}
+
+func query(issuer string, user string) string {
+	if user == "" {
+		return issuer
+	}
+	return issuer + ":" + user
+}
@@ -1,31 +1,52 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
-	"errors"
-	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/ozgur-yalcin/mfa/otp"
 	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+	"github.com/ozgur-yalcin/mfa/src/tui"
 )
 
+// setFlags are the flags that change a field of the account, fields whose
+// flag is not given are kept.
+var setFlags = []string{"issuer", "user", "mode", "hash", "digits", "period", "counter", "suite"}
+
 type setCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
+	issuer   string
+	user     string
 	mode     string
 	hash     string
 	digits   int
 	period   int64
 	counter  int64
+	suite    string
+	code     string
+	input    otp.OCRAInput
+	window   int
+	match    database.MatchMode
+	dryRun   bool
 }
 
+// set matches exactly unless told otherwise, so a secret key is never written
+// to an account that only resembles the name given.
 func newSetCommand() *setCommand {
-	return &setCommand{name: "set"}
+	return &setCommand{name: "set", match: database.MatchExact}
 }
 
 func (c *setCommand) Name() string {
@@ -36,25 +57,51 @@ func (c *setCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *setCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *setCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]> [secret-key]",
+		Short:   "change the secret key, name or parameters of an account",
+		Long:    "Change the secret key, name or parameters of an account. Only the secret key\nand the fields whose flags are given change, the changes are listed. With\n--dry-run the changes are only listed. A new secret key is saved once a code of\nit is checked, given with --code or asked for in a terminal. A new name is\nchecked like account rename does.",
+		Example: "mfa account set --code 123456 GitHub:ozgur-yalcin 5BRSSSBJUWBQBOXE\nmfa account set --dry-run --hash SHA256 --digits 8 GitHub\nmfa account set --issuer GitLab --user ozgur GitHub:ozgur-yalcin",
+	}
+}
+
 func (c *setCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
-	c.fs.StringVar(&c.mode, "mode", "totp", "use time-variant TOTP mode or use event-based HOTP mode")
-	c.fs.StringVar(&c.mode, "m", "totp", "use time-variant TOTP mode or use event-based HOTP mode (shorthand)")
-	c.fs.StringVar(&c.hash, "hash", "SHA1", "A cryptographic hash method H")
-	c.fs.StringVar(&c.hash, "H", "SHA1", "A cryptographic hash method H (shorthand)")
-	c.fs.IntVar(&c.digits, "digits", 6, "A HOTP value digits d")
-	c.fs.IntVar(&c.digits, "l", 6, "A HOTP value digits d (shorthand)")
-	c.fs.Int64Var(&c.counter, "counter", 0, "used for HOTP, A counter C, which counts the number of iterations")
-	c.fs.Int64Var(&c.counter, "c", 0, "used for HOTP, A counter C, which counts the number of iterations (shorthand)")
-	c.fs.Int64Var(&c.period, "period", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
-	c.fs.Int64Var(&c.period, "i", 30, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT (shorthand)")
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(2)
+	c.fs.StringVar(&c.issuer, "issuer", "", "", "rename the issuer of the account")
+	c.fs.StringVar(&c.user, "user", "", "", "rename the user of the account, an empty value removes it")
+	c.fs.StringVar(&c.mode, "mode", "m", "", "use time-variant TOTP mode, event-based HOTP mode, Steam Guard mode or OCRA challenge-response mode")
+	c.fs.StringVar(&c.hash, "hash", "H", "", "A cryptographic hash method H")
+	c.fs.IntVar(&c.digits, "digits", "l", 0, "A HOTP value digits d")
+	c.fs.Int64Var(&c.counter, "counter", "c", 0, "used for HOTP, A counter C, which counts the number of iterations")
+	c.fs.Int64Var(&c.period, "period", "i", 0, "used for TOTP, an period (Tx) which will be used to calculate the value of the counter CT")
+	c.fs.StringVar(&c.suite, "suite", "", "", "used for OCRA, the RFC 6287 suite such as OCRA-1:HOTP-SHA1-6:QN08")
+	c.fs.StringVar(&c.code, "code", "", "", "a code of the new secret key, checked before it is saved")
+	c.fs.StringVar(&c.input.Challenge, "challenge", "", "", "used for OCRA, the challenge question Q the code answers")
+	c.fs.StringVar(&c.input.PIN, "pin", "", "", "used for OCRA, the PIN P for suites with a PIN input")
+	c.fs.StringVar(&c.input.Session, "session", "", "", "used for OCRA, the hex encoded session information S for suites with a session input")
+	c.fs.IntVar(&c.window, "window", "w", 1, "number of TOTP steps before and after now, or HOTP counters ahead, accepted for the code")
+	c.fs.Var(&c.match, "match", "", "how the issuer and user are matched (auto, exact, substring, glob, regex)")
+	c.fs.BoolVar(&c.dryRun, "dry-run", "n", false, "list the changes without saving them or checking a code")
 }
 
 func (c *setCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
-	initialize.Init()
 	if err := c.fs.Parse(args); err != nil {
 		return err
 	}
+	if c.fs.Changed("digits") {
+		if err := otp.ValidateDigits(c.digits); err != nil {
+			return invalidError(err)
+		}
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
 	var issuer, user, secret string
 	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
 		issuer = pairs[0]
@@ -65,65 +112,227 @@ func (c *setCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err
 		secret = c.fs.Arg(1)
 	}
 	if issuer == "" {
-		return errors.New("issuer cannot be empty")
+		return usageError("issuer cannot be empty")
 	}
-	if secret == "" {
-		return errors.New("secret cannot be empty")
+	if secret == "" && !c.changed() {
+		return usageError("nothing to change, give a secret key or the flags of the fields to change")
 	}
-	if _, err := c.generateCode(secret); err != nil {
-		return err
+	if secret != "" {
+		if err := otp.ValidateSecret(secret); err != nil {
+			return invalidError(err)
+		}
 	}
-	if err := c.setAccount(issuer, user, secret); err != nil {
+	before, after, err := c.setAccount(issuer, user, secret)
+	if err != nil {
 		return err
 	}
-	log.Println("account updated successfully")
-	return
+	result := output.Result{
+		Message:  "account updated successfully",
+		Accounts: []output.Account{accountOutput(after)},
+		Changes:  accountChanges(before, after),
+	}
+	if len(result.Changes) == 0 {
+		result.Message = "account is unchanged"
+	} else if c.dryRun {
+		result.Message = "account would be updated, nothing is saved"
+	}
+	return report(cd, result, func() error {
+		log.Println(result.Message)
+		value := func(value string) string {
+			if value == "" {
+				return `""`
+			}
+			return value
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 8, 8, 1, '\t', 0)
+		for _, change := range result.Changes {
+			if change.Field == "secret" {
+				fmt.Fprintf(writer, "%s:\tchanged\n", change.Field)
+				continue
+			}
+			fmt.Fprintf(writer, "%s:\t%s -> %s\n", change.Field, value(change.Before), value(change.After))
+		}
+		return writer.Flush()
+	})
 }
 
-func (c *setCommand) generateCode(secret string) (code string, err error) {
-	if c.mode == "hotp" {
-		hotp := otp.NewHOTP(c.hash, c.digits, c.counter)
-		code, err = hotp.GeneratePassCode(secret)
-	} else if c.mode == "totp" {
-		totp := otp.NewTOTP(c.hash, c.digits, c.period)
-		code, err = totp.GeneratePassCode(secret)
-	} else {
-		return code, errors.New("mode should be hotp or totp")
+// changed reports whether a flag changing a field of the account is given.
+func (c *setCommand) changed() bool {
+	for _, name := range setFlags {
+		if c.fs.Changed(name) {
+			return true
+		}
 	}
-	if err != nil {
-		return code, err
+	return false
+}
+
+// update returns account with the secret and the fields whose flags are given
+// changed.
+func (c *setCommand) update(account models.Account, secret string) models.Account {
+	if secret != "" {
+		account.Secret = secret
 	}
-	return
+	if c.fs.Changed("issuer") {
+		account.Issuer = c.issuer
+	}
+	if c.fs.Changed("user") {
+		account.User = c.user
+	}
+	if c.fs.Changed("mode") {
+		account.Mode = c.mode
+	}
+	if c.fs.Changed("hash") {
+		account.Hash = c.hash
+	}
+	if c.fs.Changed("digits") {
+		account.Digits = c.digits
+	}
+	if c.fs.Changed("period") {
+		account.Period = c.period
+	}
+	if c.fs.Changed("counter") {
+		account.Counter = c.counter
+	}
+	if c.fs.Changed("suite") {
+		account.Suite = c.suite
+	}
+	return account
+}
+
+func (c *setCommand) setAccount(issuer string, user string, secret string) (before models.Account, after models.Account, err error) {
+	update := func(account models.Account) models.Account {
+		return c.update(account, secret)
+	}
+	check := func(before models.Account, after models.Account) (models.Account, error) {
+		if after.Secret == before.Secret {
+			return after, nil
+		}
+		return c.checkCode(after)
+	}
+	return changeAccount(issuer, user, c.match, update, check, c.dryRun)
 }
 
-func (c *setCommand) setAccount(issuer string, user string, secret string) (err error) {
+// changeAccount selects the account issuer and user point to and applies
+// update to it, the changed account is saved once check passes unless dryRun
+// is set. set and rename both go through it, so a new name is checked the
+// same way.
+func changeAccount(issuer string, user string, match database.MatchMode, update func(models.Account) models.Account, check func(before models.Account, after models.Account) (models.Account, error), dryRun bool) (before models.Account, after models.Account, err error) {
 	db, err := database.LoadDatabase()
 	if err != nil {
-		return err
+		return before, after, err
 	}
 	if err := db.Open(); err != nil {
-		return err
+		return before, after, err
 	}
 	defer db.Close()
-	accounts, err := db.ListAccounts(issuer, user)
+	matches, err := searchAccounts(db, issuer, user, match)
 	if err != nil {
-		return err
+		return before, after, err
 	}
-	if len(accounts) == 0 {
-		return errors.New("account not found")
-	} else if len(accounts) > 1 {
-		return errors.New("multiple accounts found")
-	} else if len(accounts) == 1 {
-		account := db.GetAccount(issuer, user)
-		account.Issuer = issuer
-		account.User = user
-		account.Secret = secret
-		account.Mode = c.mode
-		account.Hash = c.hash
-		account.Digits = c.digits
-		account.Counter = c.counter
-		account.Period = c.period
-		return db.SetAccount(account)
+	if before, err = selectExactAccount(query(issuer, user), matches); err != nil {
+		return before, after, err
+	}
+	after = update(before)
+	if after.Issuer == "" {
+		return before, after, usageError("issuer cannot be empty")
+	}
+	if err := checkAccount(after); err != nil {
+		return before, after, invalidError(err)
+	}
+	if after.Issuer != before.Issuer || after.User != before.User {
+		if err := checkName(db, before, after.Issuer, after.User); err != nil {
+			return before, after, err
+		}
+	}
+	if dryRun || after == before {
+		return before, after, nil
+	}
+	if check != nil {
+		if after, err = check(before, after); err != nil {
+			return before, after, err
+		}
+	}
+	return before, after, db.SetAccount(after)
+}
+
+// checkCode makes sure the new secret key of account is the one the service
+// uses, with a code given by --code or asked for in a terminal. A checked HOTP
+// code is used up, the counter moves past it.
+func (c *setCommand) checkCode(account models.Account) (models.Account, error) {
+	code := c.code
+	if code == "" {
+		if !tui.IsTerminal(int(os.Stdin.Fd())) || !tui.IsTerminal(int(os.Stderr.Fd())) {
+			return account, usageError("give a code of the new secret key with --code")
+		}
+		fmt.Fprintf(os.Stderr, "code of the new secret key of %s: ", accountName(account))
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if code = strings.TrimSpace(line); code == "" {
+			return account, usageError("code cannot be empty")
+		}
+	}
+	switch account.Mode {
+	case "hotp":
+		hotp, err := otp.NewHOTP(account.Hash, account.Digits, account.Counter)
+		if err != nil {
+			return account, err
+		}
+		counter, ok, err := hotp.Match(account.Secret, code, c.window)
+		if err != nil {
+			return account, err
+		}
+		if !ok {
+			return account, errInvalidCode
+		}
+		account.Counter = counter + 1
+	case "ocra":
+		if c.input.Challenge == "" {
+			return account, usageError("give the challenge the code answers with --challenge")
+		}
+		ocra, err := otp.NewOCRA(account.Suite)
+		if err != nil {
+			return account, err
+		}
+		input := c.input
+		input.Counter = account.Counter
+		ok, err := ocra.Validate(account.Secret, code, input)
+		if err != nil {
+			return account, err
+		}
+		if !ok {
+			return account, errInvalidCode
+		}
+		if ocra.Suite().Counter {
+			account.Counter++
+		}
+	default:
+		ok, err := account.Validate(code, c.window)
+		if err != nil {
+			return account, err
+		}
+		if !ok {
+			return account, errInvalidCode
+		}
+	}
+	return account, nil
+}
+
+// accountChanges lists the fields that differ between before and after.
+func accountChanges(before models.Account, after models.Account) (changes []output.Change) {
+	field := func(name string, before string, after string) {
+		if before != after {
+			changes = append(changes, output.Change{Field: name, Before: before, After: after})
+		}
+	}
+	field("issuer", before.Issuer, after.Issuer)
+	field("user", before.User, after.User)
+	if before.Secret != after.Secret {
+		changes = append(changes, output.Change{Field: "secret"})
 	}
+	field("mode", before.Mode, after.Mode)
+	field("hash", before.Hash, after.Hash)
+	field("digits", strconv.Itoa(before.Digits), strconv.Itoa(after.Digits))
+	field("period", strconv.FormatInt(before.Period, 10), strconv.FormatInt(after.Period, 10))
+	field("counter", strconv.FormatInt(before.Counter, 10), strconv.FormatInt(after.Counter, 10))
+	field("suite", before.Suite, after.Suite)
 	return
 }
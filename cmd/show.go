@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+	"github.com/ozgur-yalcin/mfa/src/render"
+)
+
+type showCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	qr       bool
+	invert   bool
+	format   string
+	margin   int
+}
+
+func newShowCommand() *showCommand {
+	return &showCommand{name: "show"}
+}
+
+func (c *showCommand) Name() string {
+	return c.name
+}
+
+func (c *showCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *showCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *showCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]>",
+		Short:   "show the parameters and code of an account",
+		Example: "mfa account show --qr GitHub:ozgur-yalcin",
+	}
+}
+
+func (c *showCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+	c.fs.BoolVar(&c.qr, "qr", "", false, "also draw the provisioning QR code in the terminal")
+	c.fs.BoolVar(&c.invert, "invert", "", false, "draw the QR code for terminals with a light background")
+	c.fs.StringVar(&c.format, "qr-format", "", "auto", "how to draw the QR code (auto, blocks, sixel, kitty, iterm)")
+	c.fs.IntVar(&c.margin, "margin", "", 4, "quiet zone around the QR code in modules")
+}
+
+func (c *showCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	if c.margin < 0 {
+		return usageError("margin cannot be negative")
+	}
+	format, err := render.ParseFormat(c.format)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	account, err := c.getAccount(issuer, user)
+	if err != nil {
+		return err
+	}
+	if outputFormat(cd).Structured() {
+		return c.reportAccount(cd, account)
+	}
+	return c.showAccount(account, format)
+}
+
+func (c *showCommand) reportAccount(cd *Ancestor, account models.Account) (err error) {
+	item := accountOutput(account)
+	if account.Mode == "totp" || account.Mode == "steam" {
+		if item.Code, err = account.OTP(); err != nil {
+			return err
+		}
+		item.Remaining = remaining(account.Period)
+	}
+	if c.qr {
+		if item.URI, err = account.URI(); err != nil {
+			return err
+		}
+	}
+	return report(cd, output.Result{Accounts: []output.Account{item}}, nil)
+}
+
+func (c *showCommand) showAccount(account models.Account, format render.Format) (err error) {
+	code := "-"
+	if account.Mode == "totp" || account.Mode == "steam" {
+		if code, err = account.OTP(); err != nil {
+			return err
+		}
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintf(writer, "Issuer:\t%s\n", account.Issuer)
+	fmt.Fprintf(writer, "User:\t%s\n", account.User)
+	fmt.Fprintf(writer, "Mode:\t%s\n", account.Mode)
+	if account.Mode == "ocra" {
+		fmt.Fprintf(writer, "Suite:\t%s\n", account.Suite)
+	} else if account.Mode != "steam" {
+		fmt.Fprintf(writer, "Hash:\t%s\n", account.Hash)
+		fmt.Fprintf(writer, "Digits:\t%d\n", account.Digits)
+	}
+	if account.Mode == "hotp" || account.Mode == "ocra" {
+		fmt.Fprintf(writer, "Counter:\t%d\n", account.Counter)
+	} else {
+		fmt.Fprintf(writer, "Period:\t%d\n", account.Period)
+	}
+	fmt.Fprintf(writer, "Code:\t%s\n", code)
+	writer.Flush()
+	if !c.qr {
+		return
+	}
+	uri, err := account.URI()
+	if err != nil {
+		return err
+	}
+	hints := map[lib.EncodeHintType]interface{}{
+		lib.EncodeHintType_MARGIN: c.margin,
+	}
+	matrix, err := qrcode.NewQRCodeWriter().Encode(uri, lib.BarcodeFormat_QR_CODE, 0, 0, hints)
+	if err != nil {
+		return err
+	}
+	return render.Display(os.Stdout, matrix, format, c.invert)
+}
+
+func (c *showCommand) getAccount(issuer string, user string) (account models.Account, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return account, err
+	}
+	if err := db.Open(); err != nil {
+		return account, err
+	}
+	defer db.Close()
+	return findAccount(db, issuer, user)
+}
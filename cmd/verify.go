@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+)
+
+type verifyCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+	window   int
+}
+
+func newVerifyCommand() *verifyCommand {
+	return &verifyCommand{name: "verify"}
+}
+
+func (c *verifyCommand) Name() string {
+	return c.name
+}
+
+func (c *verifyCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *verifyCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *verifyCommand) Help() Help {
+	return Help{
+		Usage:   "[flags] <issuer[:user]> <code>",
+		Short:   "check a code against an account",
+		Long:    "Check a code against an account, the command exits with code 6 when it does\nnot match.",
+		Example: "mfa verify GitHub 123456\nmfa verify -w 2 GitHub:ozgur-yalcin 123456",
+	}
+}
+
+func (c *verifyCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(2)
+	c.fs.IntVar(&c.window, "window", "w", 1, "number of TOTP steps before and after now, or HOTP counters ahead, that are also accepted")
+}
+
+func (c *verifyCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	code := c.fs.Arg(1)
+	if issuer == "" {
+		return usageError("issuer cannot be empty")
+	}
+	if code == "" {
+		return usageError("code cannot be empty")
+	}
+	account, err := c.verifyAccount(issuer, user, code)
+	if err != nil {
+		return err
+	}
+	return report(cd, output.Result{
+		Message:  "code is valid",
+		Accounts: []output.Account{accountOutput(account)},
+	}, nil)
+}
+
+func (c *verifyCommand) verifyAccount(issuer string, user string, code string) (account models.Account, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return account, err
+	}
+	if err := db.Open(); err != nil {
+		return account, err
+	}
+	defer db.Close()
+	matches, err := searchAccounts(db, issuer, user, database.MatchAuto)
+	if err != nil {
+		return account, err
+	}
+	if account, err = selectAccount(query(issuer, user), matches); err != nil {
+		return account, err
+	}
+	if account.Mode == "hotp" && len(matches) == 1 {
+		// a checked HOTP code moves the counter on, so an account that only
+		// resembles the name given has to be picked
+		if account, err = selectExactAccount(query(issuer, user), matches); err != nil {
+			return account, err
+		}
+		hotp, err := otp.NewHOTP(account.Hash, account.Digits, account.Counter)
+		if err != nil {
+			return account, err
+		}
+		counter, ok, err := hotp.Match(account.Secret, code, c.window)
+		if err != nil {
+			return account, err
+		}
+		if !ok {
+			return account, errInvalidCode
+		}
+		if err := db.AdvanceCounter(account, counter+1); err != nil {
+			return account, err
+		}
+		account.Counter = counter + 1
+		return account, nil
+	}
+	ok, err := account.Validate(code, c.window)
+	if err != nil {
+		return account, err
+	}
+	if !ok {
+		return account, errInvalidCode
+	}
+	return
+}
@@ -2,15 +2,16 @@ package cmd
 
 import (
 	"context"
-	"flag"
 	"log"
 
+	"github.com/ozgur-yalcin/mfa/src/flags"
 	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/output"
 )
 
 type versionCommand struct {
 	r        *rootCommand
-	fs       *flag.FlagSet
+	fs       *flags.FlagSet
 	commands []Commander
 	name     string
 }
@@ -27,13 +28,29 @@ func (c *versionCommand) Commands() []Commander {
 	return c.commands
 }
 
+func (c *versionCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *versionCommand) Help() Help {
+	return Help{
+		Short: "print the version",
+	}
+}
+
 func (c *versionCommand) Init(cd *Ancestor) {
-	c.fs = flag.NewFlagSet(c.name, flag.ExitOnError)
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(0)
 }
 
 func (c *versionCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
-	c.ShowVersion()
-	return
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	return report(cd, output.Result{Message: initialize.Version}, func() error {
+		c.ShowVersion()
+		return nil
+	})
 }
 
 func (c *versionCommand) ShowVersion() {
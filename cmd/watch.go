@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ozgur-yalcin/mfa/src/database"
+	"github.com/ozgur-yalcin/mfa/src/flags"
+	"github.com/ozgur-yalcin/mfa/src/initialize"
+	"github.com/ozgur-yalcin/mfa/src/models"
+	"github.com/ozgur-yalcin/mfa/src/output"
+	"github.com/ozgur-yalcin/mfa/src/tui"
+)
+
+const (
+	watchRefresh  = 250 * time.Millisecond
+	watchBarWidth = 20
+)
+
+type watchCommand struct {
+	r        *rootCommand
+	fs       *flags.FlagSet
+	commands []Commander
+	name     string
+}
+
+func newWatchCommand() *watchCommand {
+	return &watchCommand{name: "watch"}
+}
+
+func (c *watchCommand) Name() string {
+	return c.name
+}
+
+func (c *watchCommand) Commands() []Commander {
+	return c.commands
+}
+
+func (c *watchCommand) Flags() *flags.FlagSet {
+	return c.fs
+}
+
+func (c *watchCommand) Help() Help {
+	return Help{
+		Usage:   "[issuer[:user]]",
+		Short:   "show codes full screen as they change",
+		Long:    "Show the codes of the matching accounts full screen with a countdown of each\naccount's period. Type to filter, select with the arrow keys, enter prints the\ncode of the selected account and escape quits.",
+		Example: "mfa watch\nmfa watch GitHub",
+	}
+}
+
+func (c *watchCommand) Init(cd *Ancestor) {
+	c.fs = flags.New(c.name)
+	c.fs.SetMaxArgs(1)
+}
+
+func (c *watchCommand) Run(ctx context.Context, cd *Ancestor, args []string) (err error) {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initialize.Init(); err != nil {
+		return err
+	}
+	var issuer, user string
+	if pairs := strings.SplitN(c.fs.Arg(0), ":", 2); len(pairs) == 2 {
+		issuer = pairs[0]
+		user = pairs[1]
+	} else {
+		issuer = c.fs.Arg(0)
+	}
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !tui.IsTerminal(in) || !tui.IsTerminal(out) {
+		return usageError("watch needs a terminal")
+	}
+	accounts, err := c.listAccounts(issuer, user)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return errAccountNotFound
+	}
+	selected, err := c.watch(ctx, in, out, accounts)
+	if err != nil || selected == nil {
+		return err
+	}
+	code, err := c.code(*selected)
+	if err != nil {
+		return err
+	}
+	item := accountOutput(*selected)
+	item.Code = code
+	if selected.Mode == "totp" || selected.Mode == "steam" {
+		item.Remaining = remaining(selected.Period)
+	}
+	return report(cd, output.Result{Accounts: []output.Account{item}}, func() error {
+		fmt.Println(code)
+		return nil
+	})
+}
+
+// watch runs the screen with the terminal in raw mode and restores the
+// terminal on the way out, also when drawing panics.
+func (c *watchCommand) watch(ctx context.Context, in int, out int, accounts []models.Account) (*models.Account, error) {
+	state, err := tui.MakeRaw(in)
+	if err != nil {
+		return nil, err
+	}
+	defer tui.Restore(in, state)
+	os.Stdout.WriteString(tui.EnterAltScreen + tui.HideCursor)
+	defer os.Stdout.WriteString(tui.ShowCursor + tui.ExitAltScreen)
+	view := &watchView{accounts: accounts}
+	return view.loop(ctx, out)
+}
+
+// code returns the code of the chosen account, HOTP counters are advanced.
+func (c *watchCommand) code(account models.Account) (code string, err error) {
+	if account.Mode != "hotp" {
+		return account.OTP()
+	}
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return code, err
+	}
+	if err := db.Open(); err != nil {
+		return code, err
+	}
+	defer db.Close()
+	_, code, err = db.NextCode(account.ID)
+	return
+}
+
+func (c *watchCommand) listAccounts(issuer string, user string) (accounts []models.Account, err error) {
+	db, err := database.LoadDatabase()
+	if err != nil {
+		return accounts, err
+	}
+	if err := db.Open(); err != nil {
+		return accounts, err
+	}
+	defer db.Close()
+	matches, err := searchAccounts(db, issuer, user, database.MatchAuto)
+	return matchedAccounts(matches), err
+}
+
+type watchView struct {
+	accounts []models.Account
+	filter   []rune
+	selected int
+	offset   int
+}
+
+// loop draws the accounts until one is chosen with enter or the user quits,
+// in which case the returned account is nil.
+func (v *watchView) loop(ctx context.Context, fd int) (*models.Account, error) {
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			input <- bytes.Clone(buf[:n])
+		}
+	}()
+	resize := make(chan os.Signal, 1)
+	tui.NotifyResize(resize)
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(resize)
+	defer signal.Stop(quit)
+	ticker := time.NewTicker(watchRefresh)
+	defer ticker.Stop()
+	for {
+		width, height, err := tui.Size(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		os.Stdout.Write(v.render(width, height, time.Now()))
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-quit:
+			return nil, nil
+		case <-resize:
+		case <-ticker.C:
+		case b, ok := <-input:
+			if !ok {
+				return nil, nil
+			}
+			for _, key := range tui.ParseKeys(b) {
+				if done, account := v.handle(key); done {
+					return account, nil
+				}
+			}
+		}
+	}
+}
+
+func (v *watchView) visible() (accounts []*models.Account) {
+	filter := strings.ToLower(string(v.filter))
+	for i := range v.accounts {
+		name := strings.ToLower(v.accounts[i].Issuer + ":" + v.accounts[i].User)
+		if strings.Contains(name, filter) {
+			accounts = append(accounts, &v.accounts[i])
+		}
+	}
+	return
+}
+
+func (v *watchView) handle(key tui.Key) (done bool, account *models.Account) {
+	visible := v.visible()
+	switch key.Code {
+	case tui.KeyInterrupt, tui.KeyEOF:
+		return true, nil
+	case tui.KeyEscape:
+		if len(v.filter) == 0 {
+			return true, nil
+		}
+		v.filter = nil
+	case tui.KeyEnter:
+		if v.selected < len(visible) {
+			return true, visible[v.selected]
+		}
+	case tui.KeyUp:
+		v.selected--
+	case tui.KeyDown, tui.KeyTab:
+		v.selected++
+	case tui.KeyPageUp:
+		v.selected -= 10
+	case tui.KeyPageDown:
+		v.selected += 10
+	case tui.KeyHome:
+		v.selected = 0
+	case tui.KeyEnd:
+		v.selected = len(visible) - 1
+	case tui.KeyBackspace:
+		if len(v.filter) > 0 {
+			v.filter = v.filter[:len(v.filter)-1]
+		}
+	case tui.KeyClear:
+		v.filter = nil
+	case tui.KeyRune:
+		v.filter = append(v.filter, key.Rune)
+		v.selected = 0
+	}
+	v.selected = max(0, min(v.selected, len(v.visible())-1))
+	return false, nil
+}
+
+func (v *watchView) render(width int, height int, now time.Time) []byte {
+	visible := v.visible()
+	rows := max(1, height-3)
+	if v.selected < v.offset {
+		v.offset = v.selected
+	} else if v.selected >= v.offset+rows {
+		v.offset = v.selected - rows + 1
+	}
+	v.offset = max(0, min(v.offset, len(visible)-rows))
+	issuerWidth, userWidth := len("Issuer"), len("User")
+	for _, account := range visible {
+		issuerWidth = max(issuerWidth, utf8.RuneCountInString(account.Issuer))
+		userWidth = max(userWidth, utf8.RuneCountInString(account.User))
+	}
+	issuerWidth, userWidth = min(issuerWidth, 24), min(userWidth, 32)
+	var b bytes.Buffer
+	line := func(s string) {
+		b.WriteString(tui.Truncate(s, width) + tui.Reset + tui.ClearLine + "\r\n")
+	}
+	b.WriteString(tui.Home)
+	line(fmt.Sprintf("%smfa watch%s  filter: %s_", tui.Bold, tui.Reset, string(v.filter)))
+	line(fmt.Sprintf("%s  %s  %s  %-10s %s", tui.Dim, tui.Pad("Issuer", issuerWidth), tui.Pad("User", userWidth), "Code", "Remaining"))
+	for i := v.offset; i < len(visible) && i < v.offset+rows; i++ {
+		account := visible[i]
+		prefix := "  "
+		if i == v.selected {
+			prefix = tui.Reverse + "> "
+		}
+		issuer := tui.Pad(tui.Truncate(account.Issuer, issuerWidth), issuerWidth)
+		user := tui.Pad(tui.Truncate(account.User, userWidth), userWidth)
+		line(prefix + issuer + "  " + user + "  " + v.status(*account, now))
+	}
+	if len(visible) == 0 {
+		line(tui.Dim + "  no matching accounts")
+	}
+	b.WriteString(tui.ClearBelow)
+	b.WriteString(fmt.Sprintf("\x1b[%d;1H", height))
+	b.WriteString(tui.Truncate(tui.Dim+"↑/↓ select  enter print code  esc quit  type to filter", width) + tui.Reset + tui.ClearLine)
+	return b.Bytes()
+}
+
+// status shows the code of an account and, for time based accounts, how much
+// of its own period is left.
+func (v *watchView) status(account models.Account, now time.Time) string {
+	if account.Mode != "totp" && account.Mode != "steam" {
+		return fmt.Sprintf("%-10s %s", "-", "enter for a code")
+	}
+	code, err := account.OTP()
+	if err != nil {
+		return fmt.Sprintf("%-10s %s", "error", err)
+	}
+	if account.Period <= 0 {
+		return code
+	}
+	left := account.Period - now.Unix()%account.Period
+	color := tui.Green
+	if left <= 5 {
+		color = tui.Red
+	} else if left <= 10 {
+		color = tui.Yellow
+	}
+	return fmt.Sprintf("%-10s %s%s%s %2ds", code, color, tui.Bar(left, account.Period, watchBarWidth), tui.Reset, left)
+}
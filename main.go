@@ -10,8 +10,5 @@ import (
 func main() {
 	log.SetFlags(0)
 	log.SetOutput(os.Stdout)
-	err := cmd.Execute(os.Args[1:])
-	if err != nil {
-		log.Fatal(err)
-	}
+	os.Exit(cmd.Execute(os.Args[1:]))
 }
@@ -5,7 +5,7 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
-	"encoding/base32"
+	"crypto/subtle"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -13,46 +13,121 @@ import (
 	"strings"
 )
 
+// MaxDigits is the longest code, the truncated value has 31 bits and so at
+// most 10 decimal digits.
+const MaxDigits = 10
+
+var errDigits = fmt.Errorf("digits should be a number between 1 and %d", MaxDigits)
+
 type HOTP struct {
 	hash    string
 	digits  int
 	counter int64
 }
 
-func NewHOTP(hash string, digits int, counter int64) *HOTP {
+func NewHOTP(hash string, digits int, counter int64) (*HOTP, error) {
+	if err := ValidateDigits(digits); err != nil {
+		return nil, err
+	}
 	return &HOTP{
 		hash:    hash,
 		digits:  digits,
 		counter: counter,
+	}, nil
+}
+
+// ValidateDigits checks that codes of the given length can be generated.
+func ValidateDigits(digits int) error {
+	if digits < 1 || digits > MaxDigits {
+		return errDigits
 	}
+	return nil
 }
 
 func (t *HOTP) GeneratePassCode(key string) (code string, err error) {
-	secret, err := base32.StdEncoding.DecodeString(strings.ToUpper(strings.Join(strings.Fields(key), "")))
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return code, err
+	}
+	return t.generate(secret, t.counter)
+}
+
+// Validate reports whether code matches the counter or one of the next window counters.
+func (t *HOTP) Validate(key string, code string, window int) (ok bool, err error) {
+	_, ok, err = t.Match(key, code, window)
+	return
+}
+
+// Match is like Validate but also returns the counter that produced the code,
+// so callers can resynchronize the stored counter.
+func (t *HOTP) Match(key string, code string, window int) (counter int64, ok bool, err error) {
+	if window < 0 {
+		return counter, false, errors.New("window cannot be negative")
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return counter, false, err
+	}
+	for i := 0; i <= window; i++ {
+		expected, err := t.generate(secret, t.counter+int64(i))
+		if err != nil {
+			return counter, false, err
+		}
+		if compare(expected, code) {
+			return t.counter + int64(i), true, nil
+		}
+	}
+	return counter, false, nil
+}
+
+func (t *HOTP) generate(secret []byte, counter int64) (code string, err error) {
+	truncatedCode, err := t.truncate(secret, counter)
 	if err != nil {
 		return code, err
 	}
-	sum := []byte{}
-	switch t.hash {
+	return formatCode(truncatedCode, t.digits), nil
+}
+
+func (t *HOTP) truncate(secret []byte, counter int64) (int64, error) {
+	sum, err := hmacSum(t.hash, secret, counterToBytes(counter))
+	if err != nil {
+		return 0, err
+	}
+	return truncate(sum), nil
+}
+
+func hmacSum(hash string, secret []byte, message []byte) (sum []byte, err error) {
+	switch hash {
 	case "SHA1":
 		mac := hmac.New(sha1.New, secret)
-		mac.Write(counterToBytes(t.counter))
+		mac.Write(message)
 		sum = mac.Sum(nil)
 	case "SHA256":
 		mac := hmac.New(sha256.New, secret)
-		mac.Write(counterToBytes(t.counter))
+		mac.Write(message)
 		sum = mac.Sum(nil)
 	case "SHA512":
 		mac := hmac.New(sha512.New, secret)
-		mac.Write(counterToBytes(t.counter))
+		mac.Write(message)
 		sum = mac.Sum(nil)
 	default:
-		return code, errors.New("invalid hash algorithm")
+		return sum, errors.New("invalid hash algorithm")
 	}
+	return sum, nil
+}
+
+func truncate(sum []byte) int64 {
 	offset := sum[len(sum)-1] & 0xf
 	binaryCode := binary.BigEndian.Uint32(sum[offset:])
-	verificationCode := int64(binaryCode) & 0x7FFFFFFF
-	truncatedCode := verificationCode % int64(math.Pow10(t.digits))
-	code = fmt.Sprintf(fmt.Sprintf("%%0%dd", t.digits), truncatedCode)
-	return code, err
+	return int64(binaryCode) & 0x7FFFFFFF
+}
+
+func formatCode(truncatedCode int64, digits int) string {
+	truncatedCode %= int64(math.Pow10(digits))
+	return fmt.Sprintf(fmt.Sprintf("%%0%dd", digits), truncatedCode)
+}
+
+func compare(expected string, code string) bool {
+	code = strings.Join(strings.Fields(code), "")
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1
 }
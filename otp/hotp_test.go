@@ -0,0 +1,84 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+)
+
+// RFC 4226 Appendix D
+func TestHOTPGeneratePassCode(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	codes := []string{
+		"755224",
+		"287082",
+		"359152",
+		"969429",
+		"338314",
+		"254676",
+		"287922",
+		"162583",
+		"399871",
+		"520489",
+	}
+	for counter, want := range codes {
+		hotp, err := NewHOTP("SHA1", 6, int64(counter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := hotp.GeneratePassCode(key)
+		if err != nil {
+			t.Fatalf("counter %d: %v", counter, err)
+		}
+		if code != want {
+			t.Errorf("counter %d: got %s, want %s", counter, code, want)
+		}
+	}
+}
+
+func TestHOTPMatch(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		counter int64
+		code    string
+		window  int
+		match   int64
+		ok      bool
+	}{
+		{0, "755224", 0, 0, true},
+		{0, "969429", 2, 0, false},
+		{0, "969429", 3, 3, true},
+		{4, "969429", 5, 0, false},
+	}
+	for _, tt := range tests {
+		hotp, err := NewHOTP("SHA1", 6, tt.counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter, ok, err := hotp.Match(key, tt.code, tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.ok || (ok && counter != tt.match) {
+			t.Errorf("Match(%s, %d) from %d = %d, %v; want %d, %v", tt.code, tt.window, tt.counter, counter, ok, tt.match, tt.ok)
+		}
+	}
+}
+
+func TestDigits(t *testing.T) {
+	for _, digits := range []int{-1, 0, MaxDigits + 1} {
+		if _, err := NewHOTP("SHA1", digits, 0); err == nil {
+			t.Errorf("NewHOTP: expected an error for %d digits", digits)
+		}
+		if _, err := NewTOTP("SHA1", digits, 30); err == nil {
+			t.Errorf("NewTOTP: expected an error for %d digits", digits)
+		}
+	}
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	hotp, err := NewHOTP("SHA1", MaxDigits, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, err := hotp.GeneratePassCode(key); err != nil || code != "1284755224" {
+		t.Errorf("got %s, %v, want 1284755224", code, err)
+	}
+}
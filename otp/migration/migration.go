@@ -0,0 +1,349 @@
+// Package migration decodes and encodes the otpauth-migration:// payloads
+// of the Google Authenticator "Transfer accounts" screen. The payload is a
+// MigrationPayload protocol buffer, decoded by hand to avoid a protobuf
+// dependency.
+package migration
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/otp/uri"
+)
+
+const (
+	Scheme = "otpauth-migration"
+	Host   = "offline"
+)
+
+// MaxBatchSize limits the number of QR codes of one export, the exporting
+// app splits accounts over a handful of codes.
+const MaxBatchSize = 100
+
+type Algorithm int32
+
+const (
+	AlgorithmUnspecified Algorithm = iota
+	AlgorithmSHA1
+	AlgorithmSHA256
+	AlgorithmSHA512
+	AlgorithmMD5
+)
+
+type DigitCount int32
+
+const (
+	DigitCountUnspecified DigitCount = iota
+	DigitCountSix
+	DigitCountEight
+)
+
+type Type int32
+
+const (
+	TypeUnspecified Type = iota
+	TypeHOTP
+	TypeTOTP
+)
+
+type Parameters struct {
+	Secret    []byte
+	Name      string
+	Issuer    string
+	Algorithm Algorithm
+	Digits    DigitCount
+	Type      Type
+	Counter   int64
+}
+
+type Payload struct {
+	Parameters []Parameters
+	Version    int32
+	BatchSize  int32
+	BatchIndex int32
+	BatchID    int32
+}
+
+// ParseURI decodes an otpauth-migration://offline?data=... URI.
+func ParseURI(raw string) (*Payload, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u.Scheme, Scheme) {
+		return nil, errors.New("scheme should be otpauth-migration")
+	}
+	if !strings.EqualFold(u.Host, Host) {
+		return nil, errors.New("host should be offline")
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	// an unescaped '+' in the data parameter is decoded as a space
+	value := strings.ReplaceAll(query.Get("data"), " ", "+")
+	if value == "" {
+		return nil, errors.New("migration data cannot be empty")
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration data: %w", err)
+	}
+	return Unmarshal(data)
+}
+
+func Unmarshal(data []byte) (*Payload, error) {
+	payload := &Payload{}
+	d := &decoder{data: data}
+	for !d.done() {
+		field, wire, err := d.tag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == 1 && wire == wireBytes:
+			value, err := d.bytes()
+			if err != nil {
+				return nil, err
+			}
+			parameters, err := unmarshalParameters(value)
+			if err != nil {
+				return nil, err
+			}
+			payload.Parameters = append(payload.Parameters, parameters)
+		case field >= 2 && field <= 5 && wire == wireVarint:
+			value, err := d.varint()
+			if err != nil {
+				return nil, err
+			}
+			switch field {
+			case 2:
+				payload.Version = int32(value)
+			case 3:
+				payload.BatchSize = int32(value)
+			case 4:
+				payload.BatchIndex = int32(value)
+			case 5:
+				payload.BatchID = int32(value)
+			}
+		default:
+			if err := d.skip(wire); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// a payload without batch fields is a batch of its own
+	if payload.BatchSize == 0 && payload.BatchIndex == 0 {
+		payload.BatchSize = 1
+	}
+	if payload.BatchSize < 1 || payload.BatchSize > MaxBatchSize {
+		return nil, fmt.Errorf("migration batch size should be between 1 and %d", MaxBatchSize)
+	}
+	if payload.BatchIndex < 0 || payload.BatchIndex >= payload.BatchSize {
+		return nil, errors.New("migration batch index should be less than the batch size")
+	}
+	return payload, nil
+}
+
+func unmarshalParameters(data []byte) (parameters Parameters, err error) {
+	d := &decoder{data: data}
+	for !d.done() {
+		field, wire, err := d.tag()
+		if err != nil {
+			return parameters, err
+		}
+		switch {
+		case field >= 1 && field <= 3 && wire == wireBytes:
+			value, err := d.bytes()
+			if err != nil {
+				return parameters, err
+			}
+			switch field {
+			case 1:
+				parameters.Secret = append([]byte(nil), value...)
+			case 2:
+				parameters.Name = string(value)
+			case 3:
+				parameters.Issuer = string(value)
+			}
+		case field >= 4 && field <= 7 && wire == wireVarint:
+			value, err := d.varint()
+			if err != nil {
+				return parameters, err
+			}
+			switch field {
+			case 4:
+				parameters.Algorithm = Algorithm(value)
+			case 5:
+				parameters.Digits = DigitCount(value)
+			case 6:
+				parameters.Type = Type(value)
+			case 7:
+				parameters.Counter = int64(value)
+			}
+		default:
+			if err := d.skip(wire); err != nil {
+				return parameters, err
+			}
+		}
+	}
+	return parameters, nil
+}
+
+// Key converts the parameters of one account to an otpauth key.
+func (p Parameters) Key() (*uri.Key, error) {
+	key := &uri.Key{
+		Issuer:  p.Issuer,
+		Account: p.Name,
+		Secret:  base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(p.Secret),
+		Period:  30,
+	}
+	if issuer, name, ok := strings.Cut(p.Name, ":"); ok && (p.Issuer == "" || p.Issuer == issuer) {
+		key.Issuer = strings.TrimSpace(issuer)
+		key.Account = strings.TrimSpace(name)
+	} else if p.Name == p.Issuer {
+		// accounts without a user are exported with the issuer as name
+		key.Account = ""
+	}
+	switch p.Algorithm {
+	case AlgorithmUnspecified, AlgorithmSHA1:
+		key.Algorithm = "SHA1"
+	case AlgorithmSHA256:
+		key.Algorithm = "SHA256"
+	case AlgorithmSHA512:
+		key.Algorithm = "SHA512"
+	default:
+		return nil, fmt.Errorf("unsupported migration algorithm %d", p.Algorithm)
+	}
+	switch p.Digits {
+	case DigitCountUnspecified, DigitCountSix:
+		key.Digits = 6
+	case DigitCountEight:
+		key.Digits = 8
+	default:
+		return nil, fmt.Errorf("unsupported migration digit count %d", p.Digits)
+	}
+	switch p.Type {
+	case TypeUnspecified, TypeTOTP:
+		key.Type = "totp"
+	case TypeHOTP:
+		key.Type = "hotp"
+		key.Period = 0
+		key.Counter = p.Counter
+	default:
+		return nil, fmt.Errorf("unsupported migration otp type %d", p.Type)
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (p *Payload) Marshal() []byte {
+	e := &encoder{}
+	for _, parameters := range p.Parameters {
+		e.bytes(1, parameters.marshal())
+	}
+	e.varint(2, uint64(p.Version))
+	e.varint(3, uint64(p.BatchSize))
+	e.varint(4, uint64(p.BatchIndex))
+	e.varint(5, uint64(uint32(p.BatchID)))
+	return e.data
+}
+
+// URI encodes the payload as an otpauth-migration://offline?data=... URI.
+func (p *Payload) URI() string {
+	data := base64.StdEncoding.EncodeToString(p.Marshal())
+	return Scheme + "://" + Host + "?data=" + url.QueryEscape(data)
+}
+
+func (p Parameters) marshal() []byte {
+	e := &encoder{}
+	e.bytes(1, p.Secret)
+	e.bytes(2, []byte(p.Name))
+	e.bytes(3, []byte(p.Issuer))
+	e.varint(4, uint64(p.Algorithm))
+	e.varint(5, uint64(p.Digits))
+	e.varint(6, uint64(p.Type))
+	e.varint(7, uint64(p.Counter))
+	return e.data
+}
+
+// NewParameters converts an otpauth key to migration parameters. Keys that
+// Google Authenticator cannot represent, such as 7 digit codes or a period
+// other than 30 seconds, are rejected.
+func NewParameters(key *uri.Key) (parameters Parameters, err error) {
+	secret, err := otp.DecodeSecret(key.Secret)
+	if err != nil {
+		return parameters, err
+	}
+	parameters = Parameters{
+		Secret: secret,
+		Name:   key.Account,
+		Issuer: key.Issuer,
+	}
+	if parameters.Name == "" {
+		parameters.Name = key.Issuer
+	}
+	switch strings.ToUpper(key.Algorithm) {
+	case "", "SHA1":
+		parameters.Algorithm = AlgorithmSHA1
+	case "SHA256":
+		parameters.Algorithm = AlgorithmSHA256
+	case "SHA512":
+		parameters.Algorithm = AlgorithmSHA512
+	default:
+		return parameters, fmt.Errorf("unsupported algorithm %s", key.Algorithm)
+	}
+	switch key.Digits {
+	case 0, 6:
+		parameters.Digits = DigitCountSix
+	case 8:
+		parameters.Digits = DigitCountEight
+	default:
+		return parameters, fmt.Errorf("unsupported digits %d", key.Digits)
+	}
+	switch key.Type {
+	case "totp":
+		if key.Period != 0 && key.Period != 30 {
+			return parameters, fmt.Errorf("unsupported period %d", key.Period)
+		}
+		if key.Params.Get("encoder") != "" {
+			return parameters, fmt.Errorf("unsupported encoder %s", key.Params.Get("encoder"))
+		}
+		parameters.Type = TypeTOTP
+	case "hotp":
+		parameters.Type = TypeHOTP
+		parameters.Counter = key.Counter
+	default:
+		return parameters, fmt.Errorf("unsupported type %s", key.Type)
+	}
+	return parameters, nil
+}
+
+// Batches splits the parameters into payloads of at most size accounts each,
+// numbered as one batch with the given id.
+func Batches(parameters []Parameters, size int, id int32) []*Payload {
+	if size <= 0 {
+		size = len(parameters)
+	}
+	var payloads []*Payload
+	for start := 0; start < len(parameters); start += size {
+		end := min(start+size, len(parameters))
+		payloads = append(payloads, &Payload{
+			Parameters: parameters[start:end],
+			Version:    1,
+			BatchIndex: int32(len(payloads)),
+			BatchID:    id,
+		})
+	}
+	for _, payload := range payloads {
+		payload.BatchSize = int32(len(payloads))
+	}
+	return payloads
+}
@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"testing"
+)
+
+// payload is a MigrationPayload with a TOTP account "Example:alice@example.com"
+// (secret "Hello!\xde\xad\xbe\xef", SHA1, six digits) and an HOTP account
+// "bob" of issuer "ACME" (SHA512, eight digits, counter 300).
+var payload = []byte{
+	0x0a, 0x2d,
+	0x0a, 0x0a, 'H', 'e', 'l', 'l', 'o', '!', 0xde, 0xad, 0xbe, 0xef,
+	0x12, 0x19, 'E', 'x', 'a', 'm', 'p', 'l', 'e', ':', 'a', 'l', 'i', 'c', 'e', '@', 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm',
+	0x20, 0x01, 0x28, 0x01, 0x30, 0x02,
+	0x0a, 0x20,
+	0x0a, 0x0a, '1', '2', '3', '4', '5', '6', '7', '8', '9', '0',
+	0x12, 0x03, 'b', 'o', 'b',
+	0x1a, 0x04, 'A', 'C', 'M', 'E',
+	0x20, 0x03, 0x28, 0x02, 0x30, 0x01, 0x38, 0xac, 0x02,
+	0x10, 0x01, 0x18, 0x02, 0x20, 0x01, 0x28, 0x7b,
+}
+
+func TestParseURI(t *testing.T) {
+	raw := "otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(payload))
+	p, err := ParseURI(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != 1 || p.BatchSize != 2 || p.BatchIndex != 1 || p.BatchID != 123 {
+		t.Errorf("got batch %d/%d id %d version %d", p.BatchIndex, p.BatchSize, p.BatchID, p.Version)
+	}
+	if len(p.Parameters) != 2 {
+		t.Fatalf("got %d accounts, want 2", len(p.Parameters))
+	}
+	if !bytes.Equal(p.Parameters[0].Secret, []byte("Hello!\xde\xad\xbe\xef")) {
+		t.Errorf("got secret %x", p.Parameters[0].Secret)
+	}
+	totp, err := p.Parameters[0].Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totp.Type != "totp" || totp.Issuer != "Example" || totp.Account != "alice@example.com" ||
+		totp.Secret != "JBSWY3DPEHPK3PXP" || totp.Algorithm != "SHA1" || totp.Digits != 6 || totp.Period != 30 {
+		t.Errorf("got %+v", *totp)
+	}
+	hotp, err := p.Parameters[1].Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hotp.Type != "hotp" || hotp.Issuer != "ACME" || hotp.Account != "bob" ||
+		hotp.Algorithm != "SHA512" || hotp.Digits != 8 || hotp.Counter != 300 {
+		t.Errorf("got %+v", *hotp)
+	}
+}
+
+func TestParseURIErrors(t *testing.T) {
+	for _, raw := range []string{
+		"otpauth://offline?data=CgA",
+		"otpauth-migration://online?data=CgA",
+		"otpauth-migration://offline",
+		"otpauth-migration://offline?data=%%%",
+		"otpauth-migration://offline?data=" + base64.StdEncoding.EncodeToString(payload[:20]),
+	} {
+		if _, err := ParseURI(raw); err == nil {
+			t.Errorf("%s: expected error", raw)
+		}
+	}
+}
+
+func TestUnmarshalBatch(t *testing.T) {
+	p, err := Unmarshal(payload[:len(payload)-8])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.BatchSize != 1 || p.BatchIndex != 0 {
+		t.Errorf("got batch %d/%d, want 0/1", p.BatchIndex, p.BatchSize)
+	}
+	for _, batch := range []*Payload{
+		{BatchSize: 2, BatchIndex: -1},
+		{BatchSize: 2, BatchIndex: 2},
+		{BatchSize: -1},
+		{BatchSize: MaxBatchSize + 1},
+		{BatchSize: 1 << 30, BatchIndex: 1},
+	} {
+		if _, err := Unmarshal(batch.Marshal()); err == nil {
+			t.Errorf("batch %d/%d: expected error", batch.BatchIndex, batch.BatchSize)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	p, err := Unmarshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Marshal(); !bytes.Equal(got, payload) {
+		t.Errorf("got %x, want %x", got, payload)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	p, err := Unmarshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parameters := append(p.Parameters, p.Parameters...)
+	parameters = append(parameters, p.Parameters[0])
+	batches := Batches(parameters, 2, 7)
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	for i, batch := range batches {
+		decoded, err := ParseURI(batch.URI())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.BatchIndex != int32(i) || decoded.BatchSize != 3 || decoded.BatchID != 7 || decoded.Version != 1 {
+			t.Errorf("batch %d: got %d/%d id %d version %d", i, decoded.BatchIndex, decoded.BatchSize, decoded.BatchID, decoded.Version)
+		}
+	}
+	if len(batches[2].Parameters) != 1 {
+		t.Errorf("last batch has %d accounts, want 1", len(batches[2].Parameters))
+	}
+}
+
+func TestNewParameters(t *testing.T) {
+	p, err := Unmarshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range p.Parameters {
+		key, err := want.Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := NewParameters(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Secret, want.Secret) || got.Algorithm != want.Algorithm ||
+			got.Digits != want.Digits || got.Type != want.Type || got.Counter != want.Counter {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package migration
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Protocol buffer wire types used by MigrationPayload.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("migration payload is truncated")
+
+type decoder struct {
+	data []byte
+}
+
+func (d *decoder) done() bool {
+	return len(d.data) == 0
+}
+
+func (d *decoder) varint() (uint64, error) {
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.data = d.data[n:]
+	return value, nil
+}
+
+func (d *decoder) tag() (field int, wire int, err error) {
+	value, err := d.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(value >> 3), int(value & 0x7), nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	length, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(d.data)) < length {
+		return nil, errTruncated
+	}
+	value := d.data[:length]
+	d.data = d.data[length:]
+	return value, nil
+}
+
+func (d *decoder) skip(wire int) error {
+	switch wire {
+	case wireVarint:
+		_, err := d.varint()
+		return err
+	case wireFixed64:
+		if len(d.data) < 8 {
+			return errTruncated
+		}
+		d.data = d.data[8:]
+	case wireBytes:
+		_, err := d.bytes()
+		return err
+	case wireFixed32:
+		if len(d.data) < 4 {
+			return errTruncated
+		}
+		d.data = d.data[4:]
+	default:
+		return errors.New("unsupported protobuf wire type")
+	}
+	return nil
+}
+
+type encoder struct {
+	data []byte
+}
+
+func (e *encoder) tag(field int, wire int) {
+	e.data = binary.AppendUvarint(e.data, uint64(field)<<3|uint64(wire))
+}
+
+func (e *encoder) varint(field int, value uint64) {
+	if value == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.data = binary.AppendUvarint(e.data, value)
+}
+
+func (e *encoder) bytes(field int, value []byte) {
+	if len(value) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.data = binary.AppendUvarint(e.data, uint64(len(value)))
+	e.data = append(e.data, value...)
+}
@@ -0,0 +1,253 @@
+package otp
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ocraChallengeSize = 128
+)
+
+// OCRASuite is a parsed RFC 6287 suite string such as
+// "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1".
+type OCRASuite struct {
+	Suite           string
+	Hash            string
+	Digits          int
+	Counter         bool
+	ChallengeFormat byte
+	ChallengeLength int
+	PINHash         string
+	SessionLength   int
+	TimeStep        time.Duration
+}
+
+// OCRAInput holds the data inputs of a single OCRA computation. Only the
+// inputs required by the suite are used.
+type OCRAInput struct {
+	Counter   int64
+	Challenge string
+	PIN       string
+	Session   string
+	Time      time.Time
+}
+
+type OCRA struct {
+	suite *OCRASuite
+	clock Clock
+}
+
+func NewOCRA(suite string) (*OCRA, error) {
+	s, err := ParseOCRASuite(suite)
+	if err != nil {
+		return nil, err
+	}
+	return &OCRA{
+		suite: s,
+		clock: SystemClock,
+	}, nil
+}
+
+func (t *OCRA) SetClock(clock Clock) *OCRA {
+	t.clock = clock
+	return t
+}
+
+func (t *OCRA) Suite() *OCRASuite {
+	return t.suite
+}
+
+func ParseOCRASuite(suite string) (*OCRASuite, error) {
+	parts := strings.Split(suite, ":")
+	if len(parts) != 3 {
+		return nil, errors.New("ocra suite should have three parts separated by colons")
+	}
+	if parts[0] != "OCRA-1" {
+		return nil, errors.New("unsupported ocra version")
+	}
+	s := &OCRASuite{Suite: suite}
+	function := strings.Split(parts[1], "-")
+	if len(function) != 3 || function[0] != "HOTP" {
+		return nil, errors.New("invalid ocra crypto function")
+	}
+	switch function[1] {
+	case "SHA1", "SHA256", "SHA512":
+		s.Hash = function[1]
+	default:
+		return nil, errors.New("invalid ocra hash algorithm")
+	}
+	digits, err := strconv.Atoi(function[2])
+	if err != nil || (digits != 0 && (digits < 4 || digits > 10)) {
+		return nil, errors.New("ocra truncation should be 0 or between 4 and 10")
+	}
+	s.Digits = digits
+	for i, input := range strings.Split(parts[2], "-") {
+		switch {
+		case input == "C" && i == 0:
+			s.Counter = true
+		case strings.HasPrefix(input, "Q") && len(input) == 4:
+			if s.ChallengeLength != 0 {
+				return nil, errors.New("duplicate ocra challenge input")
+			}
+			s.ChallengeFormat = input[1]
+			if !strings.ContainsRune("ANH", rune(s.ChallengeFormat)) {
+				return nil, errors.New("ocra challenge format should be A, N or H")
+			}
+			length, err := strconv.Atoi(input[2:])
+			if err != nil || length < 4 || length > 64 {
+				return nil, errors.New("ocra challenge length should be between 04 and 64")
+			}
+			s.ChallengeLength = length
+		case strings.HasPrefix(input, "P"):
+			switch input[1:] {
+			case "SHA1", "SHA256", "SHA512":
+				s.PINHash = input[1:]
+			default:
+				return nil, errors.New("invalid ocra pin hash algorithm")
+			}
+		case strings.HasPrefix(input, "S") && len(input) == 4:
+			length, err := strconv.Atoi(input[1:])
+			if err != nil || length <= 0 || length > 512 {
+				return nil, errors.New("ocra session length should be between 001 and 512")
+			}
+			s.SessionLength = length
+		case strings.HasPrefix(input, "T") && len(input) >= 3:
+			value, err := strconv.Atoi(input[1 : len(input)-1])
+			if err != nil {
+				return nil, errors.New("invalid ocra time step")
+			}
+			switch unit := input[len(input)-1]; {
+			case unit == 'S' && value >= 1 && value <= 59:
+				s.TimeStep = time.Duration(value) * time.Second
+			case unit == 'M' && value >= 1 && value <= 59:
+				s.TimeStep = time.Duration(value) * time.Minute
+			case unit == 'H' && value >= 1 && value <= 48:
+				s.TimeStep = time.Duration(value) * time.Hour
+			default:
+				return nil, errors.New("invalid ocra time step")
+			}
+		default:
+			return nil, fmt.Errorf("invalid ocra data input %q", input)
+		}
+	}
+	if s.ChallengeLength == 0 {
+		return nil, errors.New("ocra suite should have a challenge input")
+	}
+	return s, nil
+}
+
+func (t *OCRA) GeneratePassCode(key string, input OCRAInput) (code string, err error) {
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return code, err
+	}
+	message, err := t.message(input)
+	if err != nil {
+		return code, err
+	}
+	sum, err := hmacSum(t.suite.Hash, secret, message)
+	if err != nil {
+		return code, err
+	}
+	if t.suite.Digits == 0 {
+		return strings.ToUpper(hex.EncodeToString(sum)), nil
+	}
+	return formatCode(truncate(sum), t.suite.Digits), nil
+}
+
+func (t *OCRA) Validate(key string, code string, input OCRAInput) (ok bool, err error) {
+	expected, err := t.GeneratePassCode(key, input)
+	if err != nil {
+		return false, err
+	}
+	return compare(expected, strings.ToUpper(code)), nil
+}
+
+func (t *OCRA) message(input OCRAInput) (message []byte, err error) {
+	message = append([]byte(t.suite.Suite), 0x00)
+	if t.suite.Counter {
+		message = append(message, counterToBytes(input.Counter)...)
+	}
+	challenge, err := t.challenge(input.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	message = append(message, challenge...)
+	if t.suite.PINHash != "" {
+		if input.PIN == "" {
+			return nil, errors.New("ocra suite requires a pin")
+		}
+		message = append(message, pinHash(t.suite.PINHash, input.PIN)...)
+	}
+	if t.suite.SessionLength > 0 {
+		session, err := hex.DecodeString(input.Session)
+		if err != nil {
+			return nil, errors.New("ocra session should be hex encoded")
+		}
+		if len(session) > t.suite.SessionLength {
+			return nil, errors.New("ocra session is too long")
+		}
+		padded := make([]byte, t.suite.SessionLength)
+		copy(padded[len(padded)-len(session):], session)
+		message = append(message, padded...)
+	}
+	if t.suite.TimeStep > 0 {
+		at := input.Time
+		if at.IsZero() {
+			at = t.clock.Now()
+		}
+		steps := at.UTC().Unix() / int64(t.suite.TimeStep/time.Second)
+		message = append(message, counterToBytes(steps)...)
+	}
+	return message, nil
+}
+
+func (t *OCRA) challenge(challenge string) ([]byte, error) {
+	if challenge == "" {
+		return nil, errors.New("ocra suite requires a challenge")
+	}
+	if len(challenge) > t.suite.ChallengeLength {
+		return nil, errors.New("ocra challenge is too long")
+	}
+	var value string
+	switch t.suite.ChallengeFormat {
+	case 'N':
+		number, ok := new(big.Int).SetString(challenge, 10)
+		if !ok || number.Sign() < 0 {
+			return nil, errors.New("ocra challenge should be numeric")
+		}
+		value = number.Text(16)
+	case 'H':
+		if strings.Trim(challenge, "0123456789abcdefABCDEF") != "" {
+			return nil, errors.New("ocra challenge should be hexadecimal")
+		}
+		value = challenge
+	case 'A':
+		value = hex.EncodeToString([]byte(challenge))
+	}
+	value += strings.Repeat("0", ocraChallengeSize*2-len(value))
+	return hex.DecodeString(value)
+}
+
+func pinHash(hash string, pin string) []byte {
+	switch hash {
+	case "SHA256":
+		sum := sha256.Sum256([]byte(pin))
+		return sum[:]
+	case "SHA512":
+		sum := sha512.Sum512([]byte(pin))
+		return sum[:]
+	default:
+		sum := sha1.Sum([]byte(pin))
+		return sum[:]
+	}
+}
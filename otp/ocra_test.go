@@ -0,0 +1,77 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6287 Appendix C
+func TestOCRAGeneratePassCode(t *testing.T) {
+	key20 := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	key32 := base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012"))
+	key64 := base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234"))
+	tests := []struct {
+		suite string
+		key   string
+		input OCRAInput
+		code  string
+	}{
+		{"OCRA-1:HOTP-SHA1-6:QN08", key20, OCRAInput{Challenge: "00000000"}, "237653"},
+		{"OCRA-1:HOTP-SHA1-6:QN08", key20, OCRAInput{Challenge: "11111111"}, "243178"},
+		{"OCRA-1:HOTP-SHA1-6:QN08", key20, OCRAInput{Challenge: "22222222"}, "653583"},
+		{"OCRA-1:HOTP-SHA1-6:QN08", key20, OCRAInput{Challenge: "99999999"}, "294470"},
+		{"OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key32, OCRAInput{Counter: 0, Challenge: "12345678", PIN: "1234"}, "65347737"},
+		{"OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key32, OCRAInput{Counter: 1, Challenge: "12345678", PIN: "1234"}, "86775851"},
+		{"OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key32, OCRAInput{Counter: 9, Challenge: "12345678", PIN: "1234"}, "08522129"},
+		{"OCRA-1:HOTP-SHA256-8:QN08-PSHA1", key32, OCRAInput{Challenge: "00000000", PIN: "1234"}, "83238735"},
+		{"OCRA-1:HOTP-SHA256-8:QN08-PSHA1", key32, OCRAInput{Challenge: "44444444", PIN: "1234"}, "86807031"},
+		{"OCRA-1:HOTP-SHA512-8:C-QN08", key64, OCRAInput{Counter: 0, Challenge: "00000000"}, "07016083"},
+		{"OCRA-1:HOTP-SHA512-8:C-QN08", key64, OCRAInput{Counter: 9, Challenge: "99999999"}, "31409299"},
+		{"OCRA-1:HOTP-SHA512-8:QN08-T1M", key64, OCRAInput{Challenge: "00000000", Time: time.Unix(0x132d0b6*60, 0)}, "95209754"},
+		{"OCRA-1:HOTP-SHA512-8:QN08-T1M", key64, OCRAInput{Challenge: "44444444", Time: time.Unix(0x132d0b6*60, 0)}, "36209546"},
+	}
+	for _, tt := range tests {
+		ocra, err := NewOCRA(tt.suite)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.suite, err)
+		}
+		code, err := ocra.GeneratePassCode(tt.key, tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.suite, err)
+		}
+		if code != tt.code {
+			t.Errorf("%s %+v: got %s, want %s", tt.suite, tt.input, code, tt.code)
+		}
+	}
+}
+
+func TestParseOCRASuite(t *testing.T) {
+	valid := []string{
+		"OCRA-1:HOTP-SHA1-6:QN08",
+		"OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1",
+		"OCRA-1:HOTP-SHA512-0:QA64-S128-T30S",
+		"OCRA-1:HOTP-SHA1-4:QH40-T48H",
+	}
+	for _, suite := range valid {
+		if _, err := ParseOCRASuite(suite); err != nil {
+			t.Errorf("%s: %v", suite, err)
+		}
+	}
+	invalid := []string{
+		"",
+		"OCRA-2:HOTP-SHA1-6:QN08",
+		"OCRA-1:HOTP-MD5-6:QN08",
+		"OCRA-1:HOTP-SHA1-3:QN08",
+		"OCRA-1:HOTP-SHA1-6:C",
+		"OCRA-1:HOTP-SHA1-6:QX08",
+		"OCRA-1:HOTP-SHA1-6:QN65",
+		"OCRA-1:HOTP-SHA1-6:QN08-T60M",
+		"OCRA-1:HOTP-SHA1-6:QN08-C",
+	}
+	for _, suite := range invalid {
+		if _, err := ParseOCRASuite(suite); err == nil {
+			t.Errorf("%s: expected error", suite)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package otp
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinSecretLength is the shortest accepted key in bytes (80 bits), which
+	// is what most providers hand out.
+	MinSecretLength = 10
+)
+
+// DecodeSecret decodes a shared secret. Secrets are base32 by default, with
+// or without padding, in any case and with spaces or dashes as separators.
+// Hex and base64 secrets are accepted with a "hex:" or "base64:" prefix.
+func DecodeSecret(key string) (secret []byte, err error) {
+	key = strings.TrimSpace(key)
+	scheme, value := "base32", key
+	if pairs := strings.SplitN(key, ":", 2); len(pairs) == 2 {
+		scheme, value = strings.ToLower(pairs[0]), pairs[1]
+	}
+	switch scheme {
+	case "base32":
+		value = strings.ToUpper(strings.TrimRight(normalizeSecret(value), "="))
+		secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(value)
+	case "hex":
+		value = strings.TrimPrefix(strings.ToLower(normalizeSecret(value)), "0x")
+		secret, err = hex.DecodeString(value)
+	case "base64":
+		value = strings.TrimRight(strings.Join(strings.Fields(value), ""), "=")
+		if strings.ContainsAny(value, "-_") {
+			secret, err = base64.RawURLEncoding.DecodeString(value)
+		} else {
+			secret, err = base64.RawStdEncoding.DecodeString(value)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported secret encoding %q", scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s secret: %w", scheme, err)
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("secret cannot be empty")
+	}
+	return secret, nil
+}
+
+// ValidateSecret checks that key decodes and is at least MinSecretLength bytes long.
+func ValidateSecret(key string) error {
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return err
+	}
+	if len(secret) < MinSecretLength {
+		return fmt.Errorf("secret is too short, it should be at least %d bits", MinSecretLength*8)
+	}
+	return nil
+}
+
+func normalizeSecret(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// GenerateSecret returns a random base32 encoded secret of the given size in bits.
+func GenerateSecret(bits int) (string, error) {
+	if bits < MinSecretLength*8 {
+		return "", fmt.Errorf("secret should be at least %d bits", MinSecretLength*8)
+	}
+	if bits%8 != 0 {
+		return "", errors.New("secret size should be a multiple of 8 bits")
+	}
+	secret := make([]byte, bits/8)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
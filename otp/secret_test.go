@@ -0,0 +1,76 @@
+package otp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeSecret(t *testing.T) {
+	want := []byte("12345678901234567890")
+	keys := []string{
+		"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"gezdgnbvgy3tqojqgezdgnbvgy3tqojq",
+		"GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ",
+		"gezd-gnbv-gy3t-qojq-gezd-gnbv-gy3t-qojq",
+		"base32:GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"hex:3132333435363738393031323334353637383930",
+		"HEX:31 32 33 34 35 36 37 38 39 30 31 32 33 34 35 36 37 38 39 30",
+		"hex:0x3132333435363738393031323334353637383930",
+		"base64:MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=",
+		"base64:MTIzNDU2Nzg5MDEyMzQ1Njc4OTA",
+	}
+	for _, key := range keys {
+		secret, err := DecodeSecret(key)
+		if err != nil {
+			t.Errorf("%s: %v", key, err)
+			continue
+		}
+		if !bytes.Equal(secret, want) {
+			t.Errorf("%s: got %x, want %x", key, secret, want)
+		}
+	}
+	unpadded, err := DecodeSecret("JBSWY3DPEHPK3PXPJBSWY3DPEE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unpadded) != 16 {
+		t.Errorf("unpadded secret length %d, want 16", len(unpadded))
+	}
+	for _, key := range []string{"", "hex:zz", "rot13:ABC", "GEZDGNBVGY3TQOJ1"} {
+		if _, err := DecodeSecret(key); err == nil {
+			t.Errorf("%s: expected error", key)
+		}
+	}
+}
+
+func TestValidateSecret(t *testing.T) {
+	if err := ValidateSecret("ADOO3MCCCVO5AVD6"); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateSecret("hex:0102030405"); err == nil {
+		t.Error("expected error for a 40 bit secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	key, err := GenerateSecret(160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 20 {
+		t.Errorf("secret length %d, want 20", len(secret))
+	}
+	other, _ := GenerateSecret(160)
+	if key == other {
+		t.Error("two generated secrets are equal")
+	}
+	for _, bits := range []int{0, 64, 161} {
+		if _, err := GenerateSecret(bits); err == nil {
+			t.Errorf("%d bits: expected error", bits)
+		}
+	}
+}
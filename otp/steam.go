@@ -0,0 +1,91 @@
+package otp
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+	steamDigits   = 5
+)
+
+// Steam generates Steam Guard codes, which are TOTP-SHA1 values rendered with
+// a custom 26 character alphabet instead of decimal digits.
+type Steam struct {
+	period int64
+	clock  Clock
+}
+
+func NewSteam(period int64) *Steam {
+	return &Steam{
+		period: period,
+		clock:  SystemClock,
+	}
+}
+
+func (t *Steam) SetClock(clock Clock) *Steam {
+	t.clock = clock
+	return t
+}
+
+func (t *Steam) CounterAt(at time.Time) (int64, error) {
+	if t.period <= 0 {
+		return 0, errPeriod
+	}
+	return at.UTC().Unix() / t.period, nil
+}
+
+func (t *Steam) GeneratePassCode(key string) (string, error) {
+	return t.GenerateAt(key, t.clock.Now())
+}
+
+func (t *Steam) GenerateAt(key string, at time.Time) (code string, err error) {
+	counter, err := t.CounterAt(at)
+	if err != nil {
+		return code, err
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return code, err
+	}
+	return t.generate(secret, counter)
+}
+
+func (t *Steam) Validate(key string, code string, window int) (ok bool, err error) {
+	if window < 0 {
+		return false, errors.New("window cannot be negative")
+	}
+	counter, err := t.CounterAt(t.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return false, err
+	}
+	for i := -window; i <= window; i++ {
+		expected, err := t.generate(secret, counter+int64(i))
+		if err != nil {
+			return false, err
+		}
+		if compare(expected, strings.ToUpper(code)) {
+			ok = true
+		}
+	}
+	return ok, nil
+}
+
+func (t *Steam) generate(secret []byte, counter int64) (code string, err error) {
+	fullCode, err := (&HOTP{hash: "SHA1", digits: steamDigits}).truncate(secret, counter)
+	if err != nil {
+		return code, err
+	}
+	chars := make([]byte, steamDigits)
+	for i := range chars {
+		chars[i] = steamAlphabet[fullCode%int64(len(steamAlphabet))]
+		fullCode /= int64(len(steamAlphabet))
+	}
+	return string(chars), nil
+}
@@ -0,0 +1,137 @@
+package otp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The truncated values of the RFC 4226 Appendix D key and counters, written
+// with the Steam alphabet, least significant character first.
+func TestSteamGenerateAt(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		counter   int64
+		truncated int64
+		code      string
+	}{
+		{0, 1284755224, "GG5F5"},
+		{1, 1094287082, "PV9M4"},
+		{2, 137359152, "B26KJ"},
+		{3, 1726969429, "5H85C"},
+		{4, 1640338314, "6Y9J3"},
+		{5, 868254676, "MD224"},
+		{6, 1918287922, "P2GRF"},
+		{7, 82162583, "C9PRW"},
+		{8, 673399871, "3NKKN"},
+		{9, 645520489, "5YCKB"},
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		truncated, err := (&HOTP{hash: "SHA1", digits: steamDigits}).truncate(secret, tt.counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if truncated != tt.truncated {
+			t.Errorf("counter %d: truncated %d, want %d", tt.counter, truncated, tt.truncated)
+		}
+		at := time.Unix(tt.counter*30+29, 0)
+		steam := NewSteam(30)
+		if counter, err := steam.CounterAt(at); err != nil || counter != tt.counter {
+			t.Errorf("counter %d: got counter %d", tt.counter, counter)
+		}
+		code, err := steam.GenerateAt(key, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("counter %d: got %s, want %s", tt.counter, code, tt.code)
+		}
+		code, err = steam.SetClock(FixedClock(at)).GeneratePassCode(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("counter %d: clock got %s, want %s", tt.counter, code, tt.code)
+		}
+	}
+}
+
+// Codes of the reference implementation, generate_twofactor_code_for_time of
+// the ValvePython steam package, which Steam clients are checked against.
+func TestSteamReference(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("superdupersecret"))
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{3000030, "YRGQJ"},
+		{3000029, "94R9D"},
+	}
+	for _, tt := range tests {
+		code, err := NewSteam(30).GenerateAt(key, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("%d: got %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestSteamAlphabet(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	steam := NewSteam(30)
+	for counter := int64(0); counter < 200; counter++ {
+		code, err := steam.GenerateAt(key, time.Unix(counter*30, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != steamDigits {
+			t.Fatalf("counter %d: code %q should have %d characters", counter, code, steamDigits)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(steamAlphabet, r) {
+				t.Fatalf("counter %d: code %q has %q outside the Steam alphabet", counter, code, r)
+			}
+		}
+	}
+}
+
+func TestSteamValidate(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix   int64
+		code   string
+		window int
+		ok     bool
+	}{
+		{3*30 + 1, "5H85C", 0, true},
+		{3*30 + 1, "5h85c", 0, true},
+		{3*30 + 1, "5H85D", 0, false},
+		{2*30 + 1, "5H85C", 0, false},
+		{2*30 + 1, "5H85C", 1, true},
+		{4*30 + 1, "5H85C", 1, true},
+		{5*30 + 1, "5H85C", 1, false},
+	}
+	for _, tt := range tests {
+		steam := NewSteam(30).SetClock(FixedClock(time.Unix(tt.unix, 0)))
+		ok, err := steam.Validate(key, tt.code, tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.ok {
+			t.Errorf("Validate(%s) at %d with window %d = %v, want %v", tt.code, tt.unix, tt.window, ok, tt.ok)
+		}
+	}
+	if _, err := NewSteam(30).Validate(key, "5H85C", -1); err == nil {
+		t.Error("expected an error for a negative window")
+	}
+	if _, err := NewSteam(0).Validate(key, "5H85C", 0); err == nil {
+		t.Error("expected an error for a zero period")
+	}
+}
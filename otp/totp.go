@@ -2,29 +2,88 @@ package otp
 
 import (
 	"encoding/binary"
+	"errors"
 	"time"
 )
 
+var errPeriod = errors.New("period should be greater than zero")
+
 type TOTP struct {
 	hash   string
 	digits int
 	period int64
+	clock  Clock
 }
 
-func NewTOTP(hash string, digits int, period int64) *TOTP {
+func NewTOTP(hash string, digits int, period int64) (*TOTP, error) {
+	if err := ValidateDigits(digits); err != nil {
+		return nil, err
+	}
 	return &TOTP{
 		hash:   hash,
 		digits: digits,
 		period: period,
-	}
+		clock:  SystemClock,
+	}, nil
+}
+
+// SetClock replaces the clock used to derive the current time step.
+func (t *TOTP) SetClock(clock Clock) *TOTP {
+	t.clock = clock
+	return t
 }
 
-func (t *TOTP) counter() int64 {
-	return time.Now().UTC().Unix() / t.period
+func (t *TOTP) counter() (int64, error) {
+	return t.CounterAt(t.clock.Now())
+}
+
+// CounterAt returns the time step counter for the given time.
+func (t *TOTP) CounterAt(at time.Time) (int64, error) {
+	if t.period <= 0 {
+		return 0, errPeriod
+	}
+	return at.UTC().Unix() / t.period, nil
 }
 
 func (t *TOTP) GeneratePassCode(key string) (string, error) {
-	return NewHOTP(t.hash, t.digits, t.counter()).GeneratePassCode(key)
+	return t.GenerateAt(key, t.clock.Now())
+}
+
+// GenerateAt returns the code that is valid at the given time.
+func (t *TOTP) GenerateAt(key string, at time.Time) (string, error) {
+	counter, err := t.CounterAt(at)
+	if err != nil {
+		return "", err
+	}
+	hotp := &HOTP{hash: t.hash, digits: t.digits, counter: counter}
+	return hotp.GeneratePassCode(key)
+}
+
+// Validate reports whether code matches the current time step or one of the
+// window steps before and after it.
+func (t *TOTP) Validate(key string, code string, window int) (ok bool, err error) {
+	if window < 0 {
+		return false, errors.New("window cannot be negative")
+	}
+	counter, err := t.counter()
+	if err != nil {
+		return false, err
+	}
+	secret, err := DecodeSecret(key)
+	if err != nil {
+		return false, err
+	}
+	hotp := &HOTP{hash: t.hash, digits: t.digits}
+	for i := -window; i <= window; i++ {
+		expected, err := hotp.generate(secret, counter+int64(i))
+		if err != nil {
+			return false, err
+		}
+		if compare(expected, code) {
+			ok = true
+		}
+	}
+	return ok, nil
 }
 
 func counterToBytes(counter int64) []byte {
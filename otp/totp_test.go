@@ -0,0 +1,103 @@
+package otp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B
+func TestTOTPGenerateAt(t *testing.T) {
+	keys := map[string]string{
+		"SHA1":   base32.StdEncoding.EncodeToString([]byte("12345678901234567890")),
+		"SHA256": base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012")),
+		"SHA512": base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234")),
+	}
+	tests := []struct {
+		unix    int64
+		counter int64
+		hash    string
+		code    string
+	}{
+		{59, 0x1, "SHA1", "94287082"},
+		{59, 0x1, "SHA256", "46119246"},
+		{59, 0x1, "SHA512", "90693936"},
+		{1111111109, 0x23523EC, "SHA1", "07081804"},
+		{1111111109, 0x23523EC, "SHA256", "68084774"},
+		{1111111109, 0x23523EC, "SHA512", "25091201"},
+		{1111111111, 0x23523ED, "SHA1", "14050471"},
+		{1111111111, 0x23523ED, "SHA256", "67062674"},
+		{1111111111, 0x23523ED, "SHA512", "99943326"},
+		{1234567890, 0x273EF07, "SHA1", "89005924"},
+		{1234567890, 0x273EF07, "SHA256", "91819424"},
+		{1234567890, 0x273EF07, "SHA512", "93441116"},
+		{2000000000, 0x3F940AA, "SHA1", "69279037"},
+		{2000000000, 0x3F940AA, "SHA256", "90698825"},
+		{2000000000, 0x3F940AA, "SHA512", "38618901"},
+		{20000000000, 0x27BC86AA, "SHA1", "65353130"},
+		{20000000000, 0x27BC86AA, "SHA256", "77737706"},
+		{20000000000, 0x27BC86AA, "SHA512", "47863826"},
+	}
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+		totp, err := NewTOTP(tt.hash, 8, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counter, err := totp.CounterAt(at); err != nil || counter != tt.counter {
+			t.Errorf("%s %d: counter %X, want %X", tt.hash, tt.unix, counter, tt.counter)
+		}
+		code, err := totp.GenerateAt(keys[tt.hash], at)
+		if err != nil {
+			t.Fatalf("%s %d: %v", tt.hash, tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("%s %d: got %s, want %s", tt.hash, tt.unix, code, tt.code)
+		}
+		code, err = totp.SetClock(FixedClock(at)).GeneratePassCode(keys[tt.hash])
+		if err != nil {
+			t.Fatalf("%s %d: %v", tt.hash, tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("%s %d: clock got %s, want %s", tt.hash, tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	key := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix   int64
+		window int
+		ok     bool
+	}{
+		{1111111109, 0, true},
+		{1111111109 - 30, 0, false},
+		{1111111109 - 30, 1, true},
+		{1111111109 + 30, 1, true},
+		{1111111109 + 60, 1, false},
+	}
+	for _, tt := range tests {
+		totp, err := NewTOTP("SHA1", 8, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := totp.SetClock(FixedClock(time.Unix(tt.unix, 0))).Validate(key, "07081804", tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tt.ok {
+			t.Errorf("Validate at %d with window %d = %v, want %v", tt.unix, tt.window, ok, tt.ok)
+		}
+	}
+}
+
+func TestCounterAtZeroPeriod(t *testing.T) {
+	at := time.Unix(59, 0)
+	if _, err := (&TOTP{period: 0}).CounterAt(at); err == nil {
+		t.Error("expected an error for a zero TOTP period")
+	}
+	if _, err := NewSteam(0).CounterAt(at); err == nil {
+		t.Error("expected an error for a zero Steam period")
+	}
+}
@@ -0,0 +1,39 @@
+package uri
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidURI       = errors.New("invalid uri")
+	ErrInvalidScheme    = errors.New("scheme should be otpauth")
+	ErrInvalidType      = errors.New("type should be totp or hotp")
+	ErrMissingLabel     = errors.New("label cannot be empty")
+	ErrMissingSecret    = errors.New("secret cannot be empty")
+	ErrInvalidSecret    = errors.New("secret should be base32 encoded")
+	ErrInvalidAlgorithm = errors.New("algorithm should be SHA1, SHA256 or SHA512")
+	ErrInvalidDigits    = errors.New("digits should be a number between 1 and 10")
+	ErrInvalidPeriod    = errors.New("period should be a positive number")
+	ErrMissingCounter   = errors.New("counter is required for hotp")
+	ErrInvalidCounter   = errors.New("counter should be a non-negative number")
+)
+
+// Error describes an invalid key URI parameter. Err is one of the Err*
+// variables of this package, so callers can match it with errors.Is.
+type Error struct {
+	Param string
+	Value string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("otpauth %s: %s", e.Param, e.Err)
+	}
+	return fmt.Sprintf("otpauth %s %q: %s", e.Param, e.Value, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,199 @@
+// Package uri parses and builds otpauth:// key URIs as described by the
+// Google Authenticator Key URI Format.
+package uri
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/otp"
+)
+
+const (
+	Scheme = "otpauth"
+)
+
+// Key is a decoded key URI. Numeric and algorithm fields are zero when the
+// parameter was absent, in which case the format defaults apply (SHA1, 6
+// digits, 30 seconds). Params holds every parameter this package does not
+// know, so they survive a Parse and String round trip.
+type Key struct {
+	Type      string
+	Issuer    string
+	Account   string
+	Secret    string
+	Algorithm string
+	Digits    int
+	Period    int64
+	Counter   int64
+	Image     string
+	Color     string
+	Params    url.Values
+}
+
+func Parse(raw string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, &Error{Param: "uri", Err: ErrInvalidURI}
+	}
+	if !strings.EqualFold(u.Scheme, Scheme) {
+		return nil, &Error{Param: "scheme", Value: u.Scheme, Err: ErrInvalidScheme}
+	}
+	key := &Key{
+		Type:   strings.ToLower(u.Host),
+		Params: url.Values{},
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer = strings.TrimSpace(issuer)
+		key.Account = strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, &Error{Param: "query", Value: u.RawQuery, Err: ErrInvalidURI}
+	}
+	hasCounter := false
+	for name, values := range query {
+		value := values[0]
+		switch strings.ToLower(name) {
+		case "secret":
+			key.Secret = value
+		case "issuer":
+			// the issuer parameter takes precedence over the label prefix
+			if value != "" {
+				key.Issuer = value
+			}
+		case "algorithm":
+			key.Algorithm = value
+		case "digits":
+			digits, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, &Error{Param: "digits", Value: value, Err: ErrInvalidDigits}
+			}
+			key.Digits = digits
+		case "period":
+			period, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, &Error{Param: "period", Value: value, Err: ErrInvalidPeriod}
+			}
+			key.Period = period
+		case "counter":
+			counter, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, &Error{Param: "counter", Value: value, Err: ErrInvalidCounter}
+			}
+			key.Counter = counter
+			hasCounter = true
+		case "image":
+			key.Image = value
+		case "color":
+			key.Color = value
+		default:
+			key.Params[name] = values
+		}
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	if key.Type == "hotp" && !hasCounter {
+		return nil, &Error{Param: "counter", Err: ErrMissingCounter}
+	}
+	key.Algorithm = normalizeAlgorithm(key.Algorithm)
+	key.Secret = normalizeSecret(key.Secret)
+	return key, nil
+}
+
+// Validate checks the key against the format rules.
+func (k *Key) Validate() error {
+	if k.Type != "totp" && k.Type != "hotp" {
+		return &Error{Param: "type", Value: k.Type, Err: ErrInvalidType}
+	}
+	if k.Issuer == "" && k.Account == "" {
+		return &Error{Param: "label", Err: ErrMissingLabel}
+	}
+	if k.Secret == "" {
+		return &Error{Param: "secret", Err: ErrMissingSecret}
+	}
+	if strings.Contains(k.Secret, ":") {
+		return &Error{Param: "secret", Value: k.Secret, Err: ErrInvalidSecret}
+	}
+	if _, err := otp.DecodeSecret(k.Secret); err != nil {
+		return &Error{Param: "secret", Value: k.Secret, Err: ErrInvalidSecret}
+	}
+	switch normalizeAlgorithm(k.Algorithm) {
+	case "", "SHA1", "SHA256", "SHA512":
+	default:
+		return &Error{Param: "algorithm", Value: k.Algorithm, Err: ErrInvalidAlgorithm}
+	}
+	if k.Digits < 0 || k.Digits > 10 {
+		return &Error{Param: "digits", Value: strconv.Itoa(k.Digits), Err: ErrInvalidDigits}
+	}
+	if k.Period < 0 {
+		return &Error{Param: "period", Value: strconv.FormatInt(k.Period, 10), Err: ErrInvalidPeriod}
+	}
+	if k.Counter < 0 {
+		return &Error{Param: "counter", Value: strconv.FormatInt(k.Counter, 10), Err: ErrInvalidCounter}
+	}
+	return nil
+}
+
+// String builds the key URI. Known parameters come first in a fixed order,
+// followed by the unknown ones sorted by name.
+func (k *Key) String() string {
+	label := url.PathEscape(k.Account)
+	if k.Issuer != "" {
+		label = url.PathEscape(k.Issuer) + ":" + label
+	}
+	var params []string
+	add := func(name string, value string) {
+		params = append(params, name+"="+escape(value))
+	}
+	add("secret", normalizeSecret(k.Secret))
+	if k.Issuer != "" {
+		add("issuer", k.Issuer)
+	}
+	if k.Algorithm != "" {
+		add("algorithm", normalizeAlgorithm(k.Algorithm))
+	}
+	if k.Digits != 0 {
+		add("digits", strconv.Itoa(k.Digits))
+	}
+	if k.Type == "hotp" {
+		add("counter", strconv.FormatInt(k.Counter, 10))
+	} else if k.Period != 0 {
+		add("period", strconv.FormatInt(k.Period, 10))
+	}
+	if k.Image != "" {
+		add("image", k.Image)
+	}
+	if k.Color != "" {
+		add("color", k.Color)
+	}
+	names := make([]string, 0, len(k.Params))
+	for name := range k.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range k.Params[name] {
+			add(escape(name), value)
+		}
+	}
+	return Scheme + "://" + k.Type + "/" + label + "?" + strings.Join(params, "&")
+}
+
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func normalizeAlgorithm(algorithm string) string {
+	return strings.ToUpper(strings.ReplaceAll(algorithm, "-", ""))
+}
+
+func normalizeSecret(secret string) string {
+	return strings.ToUpper(strings.TrimRight(strings.Join(strings.Fields(secret), ""), "="))
+}
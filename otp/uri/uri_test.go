@@ -0,0 +1,95 @@
+package uri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	key, err := Parse("otpauth://totp/ACME%20Co:john.doe%40email.com?secret=hxdmvjecjjwsrb3hwizr4ifugftmxboz&issuer=ACME%20Co&algorithm=sha256&digits=8&period=60&image=https%3A%2F%2Facme.co%2Flogo.png&color=FF0000&foo=bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Key{
+		Type:      "totp",
+		Issuer:    "ACME Co",
+		Account:   "john.doe@email.com",
+		Secret:    "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ",
+		Algorithm: "SHA256",
+		Digits:    8,
+		Period:    60,
+		Image:     "https://acme.co/logo.png",
+		Color:     "FF0000",
+	}
+	if key.Type != want.Type || key.Issuer != want.Issuer || key.Account != want.Account ||
+		key.Secret != want.Secret || key.Algorithm != want.Algorithm || key.Digits != want.Digits ||
+		key.Period != want.Period || key.Image != want.Image || key.Color != want.Color {
+		t.Errorf("got %+v, want %+v", *key, want)
+	}
+	if key.Params.Get("foo") != "bar" {
+		t.Errorf("unknown parameter foo = %q, want bar", key.Params.Get("foo"))
+	}
+}
+
+func TestParseLabel(t *testing.T) {
+	tests := []struct {
+		uri     string
+		issuer  string
+		account string
+	}{
+		{"otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP", "Example", "alice@google.com"},
+		{"otpauth://totp/Example%3A%20alice?secret=JBSWY3DPEHPK3PXP", "Example", "alice"},
+		{"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP", "", "alice"},
+		{"otpauth://totp/Label:alice?secret=JBSWY3DPEHPK3PXP&issuer=Param", "Param", "alice"},
+		{"otpauth://totp/Label:alice?secret=JBSWY3DPEHPK3PXP&issuer=", "Label", "alice"},
+	}
+	for _, tt := range tests {
+		key, err := Parse(tt.uri)
+		if err != nil {
+			t.Errorf("%s: %v", tt.uri, err)
+			continue
+		}
+		if key.Issuer != tt.issuer || key.Account != tt.account {
+			t.Errorf("%s: got %q %q, want %q %q", tt.uri, key.Issuer, key.Account, tt.issuer, tt.account)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		uri string
+		err error
+	}{
+		{"https://totp/a?secret=JBSWY3DPEHPK3PXP", ErrInvalidScheme},
+		{"otpauth://motp/a?secret=JBSWY3DPEHPK3PXP", ErrInvalidType},
+		{"otpauth://totp/?secret=JBSWY3DPEHPK3PXP", ErrMissingLabel},
+		{"otpauth://totp/a", ErrMissingSecret},
+		{"otpauth://totp/a?secret=JBSWY3DPEHPK3PX1", ErrInvalidSecret},
+		{"otpauth://totp/a?secret=JBSWY3DPEHPK3PXP&algorithm=MD5", ErrInvalidAlgorithm},
+		{"otpauth://totp/a?secret=JBSWY3DPEHPK3PXP&digits=six", ErrInvalidDigits},
+		{"otpauth://totp/a?secret=JBSWY3DPEHPK3PXP&period=-30", ErrInvalidPeriod},
+		{"otpauth://hotp/a?secret=JBSWY3DPEHPK3PXP", ErrMissingCounter},
+		{"otpauth://hotp/a?secret=JBSWY3DPEHPK3PXP&counter=x", ErrInvalidCounter},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.uri)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.uri, err, tt.err)
+		}
+		var uriErr *Error
+		if !errors.As(err, &uriErr) {
+			t.Errorf("%s: error %T is not *Error", tt.uri, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	raw := "otpauth://hotp/ACME%20Co:john%2Fdoe?secret=JBSWY3DPEHPK3PXP&issuer=ACME%20Co&algorithm=SHA512&digits=8&counter=7&image=https%3A%2F%2Facme.co%2Flogo.png&a=1&z=2"
+	key, err := Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := key.String(); got != raw {
+		t.Errorf("got %s, want %s", got, raw)
+	}
+}
@@ -4,6 +4,7 @@ import "fmt"
 
 type Postgresql struct {
 	engine   string
+	url      string
 	name     string
 	user     string
 	password string
@@ -17,5 +18,16 @@ func (db Postgresql) Engine() string {
 }
 
 func (db Postgresql) Params() string {
+	if db.url != "" {
+		return db.url
+	}
 	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=Europe/Istanbul", db.host, db.user, db.password, db.name, db.port, db.sslMode)
 }
+
+func NewPostgresql(host string, port int, user string, password string, name string, sslMode string) *Postgresql {
+	return &Postgresql{engine: "postgresql", host: host, port: port, user: user, password: password, name: name, sslMode: sslMode}
+}
+
+func NewPostgresqlURL(url string) *Postgresql {
+	return &Postgresql{engine: "postgresql", url: url}
+}
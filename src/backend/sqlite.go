@@ -20,3 +20,7 @@ func (db Sqlite) Params() string {
 func NewSqlite(filePath string) *Sqlite {
 	return &Sqlite{engine: "sqlite", name: filePath}
 }
+
+func (db Sqlite) Path() string {
+	return db.name
+}
@@ -0,0 +1,131 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/tui"
+)
+
+// Clipboard holds text copied by mfa until it is cleared.
+type Clipboard interface {
+	Copy(text string) error
+	// Clear empties the clipboard, when the clipboard can be read it is only
+	// emptied while it still holds text.
+	Clear(text string) error
+	Close() error
+}
+
+// Sequence returns the OSC 52 escape that sets the clipboard to text. Inside
+// tmux and GNU screen the escape is wrapped so it is passed on to the outer
+// terminal.
+func Sequence(text string, tmux bool, screen bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case tmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case screen:
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// Terminal sets the clipboard of the terminal with OSC 52, which also works
+// over SSH. Terminals do not let the clipboard be read, so Clear always
+// empties it.
+type Terminal struct {
+	w      io.Writer
+	tty    *os.File
+	tmux   bool
+	screen bool
+}
+
+// NewTerminal opens the controlling terminal of the process, or writes to
+// stderr when it is a terminal and /dev/tty cannot be opened.
+func NewTerminal() (*Terminal, error) {
+	t := &Terminal{
+		tmux:   os.Getenv("TMUX") != "",
+		screen: os.Getenv("STY") != "",
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err == nil {
+		t.w, t.tty = tty, tty
+	} else if tui.IsTerminal(int(os.Stderr.Fd())) {
+		t.w = os.Stderr
+	} else {
+		return nil, errors.New("osc 52 needs a terminal, set a clipboard command instead")
+	}
+	return t, nil
+}
+
+func (t *Terminal) Copy(text string) (err error) {
+	_, err = io.WriteString(t.w, Sequence(text, t.tmux, t.screen))
+	return
+}
+
+func (t *Terminal) Clear(text string) error {
+	return t.Copy("")
+}
+
+// Close closes the controlling terminal when NewTerminal opened it, stderr is
+// left open.
+func (t *Terminal) Close() error {
+	if t.tty == nil {
+		return nil
+	}
+	return t.tty.Close()
+}
+
+// Command copies by running a program with the text on its standard input,
+// such as "xclip -selection clipboard", "wl-copy" or "pbcopy". With a paste
+// command, such as "wl-paste -n", the clipboard is only cleared while it still
+// holds the copied text.
+type Command struct {
+	copy  []string
+	paste []string
+}
+
+// NewCommand splits the commands on spaces, no shell quoting is supported.
+func NewCommand(copy string, paste string) (*Command, error) {
+	c := &Command{copy: strings.Fields(copy), paste: strings.Fields(paste)}
+	if len(c.copy) == 0 {
+		return nil, errors.New("clipboard command cannot be empty")
+	}
+	return c, nil
+}
+
+func (c *Command) Copy(text string) error {
+	cmd := exec.Command(c.copy[0], c.copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New(c.copy[0] + ": " + msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Command) Clear(text string) error {
+	if len(c.paste) > 0 {
+		current, err := exec.Command(c.paste[0], c.paste[1:]...).Output()
+		if err != nil {
+			return err
+		}
+		if strings.TrimRight(string(current), "\r\n") != text {
+			return nil
+		}
+	}
+	return c.Copy("")
+}
+
+func (c *Command) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	if got, want := Sequence("123456", false, false), "\x1b]52;c;MTIzNDU2\a"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Sequence("123456", true, false), "\x1bPtmux;\x1b\x1b]52;c;MTIzNDU2\a\x1b\\"; got != want {
+		t.Errorf("tmux: got %q, want %q", got, want)
+	}
+	if got, want := Sequence("123456", false, true), "\x1bP\x1b]52;c;MTIzNDU2\a\x1b\\"; got != want {
+		t.Errorf("screen: got %q, want %q", got, want)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	for _, name := range []string{"tee", "cat"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skip(name, "is not available")
+		}
+	}
+	path := filepath.Join(t.TempDir(), "clipboard")
+	clip, err := NewCommand("tee "+path, "cat "+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func() string {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	if err := clip.Copy("123456"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "123456" {
+		t.Errorf("got %q after copy", got)
+	}
+	if err := os.WriteFile(path, []byte("something else\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := clip.Clear("123456"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "something else\n" {
+		t.Errorf("clear replaced text copied by someone else: %q", got)
+	}
+	clip.Copy("123456")
+	if err := clip.Clear("123456"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "" {
+		t.Errorf("got %q after clear", got)
+	}
+	if _, err := NewCommand(" ", ""); err == nil {
+		t.Error("expected an error for an empty command")
+	}
+}
+
+func TestTerminalCloseKeepsStderr(t *testing.T) {
+	term := &Terminal{w: os.Stderr}
+	if err := term.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stderr.Stat(); err != nil {
+		t.Errorf("stderr was closed: %v", err)
+	}
+}
@@ -1,15 +1,186 @@
 package config
 
 import (
-	"path"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ozgur-yalcin/mfa/src/backend"
 )
 
 const (
 	sqliteFileName = "mfa.db"
+	configFileName = "config"
+	appName        = "mfa"
 )
 
+type Database struct {
+	Engine   string
+	Path     string
+	URL      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
+
+// OTP holds the parameters used when a command is not given them.
+type OTP struct {
+	Mode   string
+	Hash   string
+	Digits int
+	Period int64
+}
+
+type Config struct {
+	Database Database
+	OTP      OTP
+}
+
+var current = New()
+
+// legacyPath is the database of the versions before the data directory.
+var legacyPath = filepath.Join(".", sqliteFileName)
+
+// New returns the built-in configuration, a SQLite database in the data
+// directory of the user and 6 digit SHA1 TOTP codes every 30 seconds.
+func New() *Config {
+	return &Config{
+		Database: Database{
+			Engine:  "sqlite",
+			Path:    DataPath(),
+			Host:    "localhost",
+			Port:    5432,
+			User:    appName,
+			Name:    appName,
+			SSLMode: "disable",
+		},
+		OTP: OTP{
+			Mode:   "totp",
+			Hash:   "SHA1",
+			Digits: 6,
+			Period: 30,
+		},
+	}
+}
+
+// Current returns the configuration in use, set by Use.
+func Current() *Config {
+	return current
+}
+
+func Use(c *Config) {
+	current = c
+}
+
 func Default() backend.Backend {
-	return backend.NewSqlite(path.Join(".", sqliteFileName))
+	return current.Backend()
+}
+
+func (c *Config) Backend() backend.Backend {
+	db := c.Database
+	if db.Engine == "postgresql" {
+		if db.URL != "" {
+			return backend.NewPostgresqlURL(db.URL)
+		}
+		return backend.NewPostgresql(db.Host, db.Port, db.User, db.Password, db.Name, db.SSLMode)
+	}
+	return backend.NewSqlite(db.Path)
+}
+
+// SetDB points the configuration at a database, a postgres:// or
+// postgresql:// URL selects PostgreSQL and anything else is a SQLite file.
+func (c *Config) SetDB(value string) error {
+	if value == "" {
+		return errors.New("database cannot be empty")
+	}
+	if lower := strings.ToLower(value); strings.HasPrefix(lower, "postgres://") || strings.HasPrefix(lower, "postgresql://") {
+		c.Database.Engine = "postgresql"
+		c.Database.URL = value
+		return nil
+	}
+	c.Database.Engine = "sqlite"
+	c.Database.Path = expand(value)
+	return nil
+}
+
+// Path returns the configuration file, $XDG_CONFIG_HOME/mfa/config or
+// ~/.config/mfa/config.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, appName, configFileName), nil
+}
+
+// DataPath returns the default SQLite database, $XDG_DATA_HOME/mfa/mfa.db or
+// ~/.local/share/mfa/mfa.db, falling back to the current directory when the
+// home directory is unknown.
+func DataPath() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, appName, sqliteFileName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", sqliteFileName)
+	}
+	return filepath.Join(home, ".local", "share", appName, sqliteFileName)
+}
+
+// MigrateLegacy copies the mfa.db in the current directory, where earlier
+// versions kept it, to the default database when a configuration left on the
+// default database is about to create it. Once the default database exists
+// the current directory is no longer looked at. It returns the path it copied,
+// or an empty string.
+func (c *Config) MigrateLegacy() (string, error) {
+	if c.Database.Engine != "sqlite" || c.Database.Path != DataPath() {
+		return "", nil
+	}
+	if _, err := os.Stat(c.Database.Path); !errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	legacy, err := os.Open(legacyPath)
+	if err != nil {
+		return "", nil
+	}
+	defer legacy.Close()
+	if info, err := legacy.Stat(); err != nil || info.IsDir() {
+		return "", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Database.Path), 0o700); err != nil {
+		return "", err
+	}
+	file, err := os.OpenFile(c.Database.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, legacy); err != nil {
+		file.Close()
+		os.Remove(c.Database.Path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(c.Database.Path)
+		return "", err
+	}
+	return legacyPath, nil
+}
+
+func expand(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
 }
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	c := New()
+	err := c.Parse(strings.NewReader(`
+# mfa configuration
+[database]
+engine = postgresql
+host = db.example.com
+port = 6543
+password = "secret # not a comment"
+
+[otp]
+hash = sha256
+digits = 8
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Database.Engine != "postgresql" || c.Database.Host != "db.example.com" || c.Database.Port != 6543 || c.Database.Password != "secret # not a comment" {
+		t.Errorf("unexpected database %+v", c.Database)
+	}
+	if c.OTP != (OTP{Mode: "totp", Hash: "SHA256", Digits: 8, Period: 30}) {
+		t.Errorf("unexpected otp %+v", c.OTP)
+	}
+	if c.Backend().Engine() != "postgresql" {
+		t.Errorf("got engine %q", c.Backend().Engine())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"[otp]\ndigits = six",
+		"[otp]\nmode = sms",
+		"[database]\nengine = mysql",
+		"colour = blue",
+		"[otp]\nperiod",
+		"[database]\npassword = \"open",
+	} {
+		if err := New().Parse(strings.NewReader(text)); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	env := map[string]string{
+		"MFA_DB":     "postgres://mfa@localhost/mfa",
+		"MFA_PERIOD": "60",
+		"MFA_MODE":   "",
+	}
+	c := New()
+	err := c.Env(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Database.Engine != "postgresql" || c.Database.URL != env["MFA_DB"] {
+		t.Errorf("unexpected database %+v", c.Database)
+	}
+	if c.OTP.Period != 60 || c.OTP.Mode != "totp" {
+		t.Errorf("unexpected otp %+v", c.OTP)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	c := New()
+	if path, err := c.MigrateLegacy(); err != nil || path != "" {
+		t.Errorf("copied %q, %v without a legacy database", path, err)
+	}
+	c.SetDB(filepath.Join(dir, "other.db"))
+	if err := os.WriteFile("mfa.db", []byte("accounts"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := c.MigrateLegacy(); err != nil || path != "" {
+		t.Errorf("copied %q, %v although a database was given", path, err)
+	}
+	c = New()
+	if path, err := c.MigrateLegacy(); err != nil || path != legacyPath {
+		t.Fatalf("got %q, %v, want %q", path, err, legacyPath)
+	}
+	if c.Database.Path != DataPath() {
+		t.Errorf("database moved to %q", c.Database.Path)
+	}
+	if b, err := os.ReadFile(DataPath()); err != nil || string(b) != "accounts" {
+		t.Errorf("got %q, %v, want the legacy database", b, err)
+	}
+	// the default database exists now, the legacy one is not looked at again
+	if err := os.WriteFile("mfa.db", []byte("other accounts"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := New().MigrateLegacy(); err != nil || path != "" {
+		t.Errorf("copied %q, %v although the default database exists", path, err)
+	}
+	if b, _ := os.ReadFile(DataPath()); string(b) != "accounts" {
+		t.Errorf("default database was overwritten with %q", b)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if _, err := Load(path, false); err != nil {
+		t.Errorf("a missing optional file should be ignored: %v", err)
+	}
+	if _, err := Load(path, true); err == nil {
+		t.Error("expected an error for a missing required file")
+	}
+	if err := os.WriteFile(path, []byte("[database]\npath = "+filepath.Join(dir, "vault.db")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Database.Engine != "sqlite" || c.Database.Path != filepath.Join(dir, "vault.db") {
+		t.Errorf("unexpected database %+v", c.Database)
+	}
+}
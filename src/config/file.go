@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// setting is a key of the configuration file, also settable from the
+// environment variable env.
+type setting struct {
+	key string
+	env string
+	set func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"database.engine", "MFA_DB_ENGINE", func(c *Config, value string) error {
+		if value != "sqlite" && value != "postgresql" {
+			return errors.New("engine should be sqlite or postgresql")
+		}
+		c.Database.Engine = value
+		return nil
+	}},
+	{"database.path", "MFA_DB_PATH", func(c *Config, value string) error {
+		c.Database.Path = expand(value)
+		return nil
+	}},
+	{"database.url", "MFA_DB_URL", func(c *Config, value string) error {
+		c.Database.URL = value
+		return nil
+	}},
+	{"database.host", "MFA_DB_HOST", func(c *Config, value string) error {
+		c.Database.Host = value
+		return nil
+	}},
+	{"database.port", "MFA_DB_PORT", func(c *Config, value string) (err error) {
+		c.Database.Port, err = positive(value)
+		return
+	}},
+	{"database.user", "MFA_DB_USER", func(c *Config, value string) error {
+		c.Database.User = value
+		return nil
+	}},
+	{"database.password", "MFA_DB_PASSWORD", func(c *Config, value string) error {
+		c.Database.Password = value
+		return nil
+	}},
+	{"database.name", "MFA_DB_NAME", func(c *Config, value string) error {
+		c.Database.Name = value
+		return nil
+	}},
+	{"database.sslmode", "MFA_DB_SSLMODE", func(c *Config, value string) error {
+		c.Database.SSLMode = value
+		return nil
+	}},
+	{"otp.mode", "MFA_MODE", func(c *Config, value string) error {
+		switch value {
+		case "totp", "hotp", "steam", "ocra":
+			c.OTP.Mode = value
+			return nil
+		}
+		return errors.New("mode should be hotp, totp, steam or ocra")
+	}},
+	{"otp.hash", "MFA_HASH", func(c *Config, value string) error {
+		switch strings.ToUpper(value) {
+		case "SHA1", "SHA256", "SHA512":
+			c.OTP.Hash = strings.ToUpper(value)
+			return nil
+		}
+		return errors.New("hash should be SHA1, SHA256 or SHA512")
+	}},
+	{"otp.digits", "MFA_DIGITS", func(c *Config, value string) (err error) {
+		c.OTP.Digits, err = positive(value)
+		return
+	}},
+	{"otp.period", "MFA_PERIOD", func(c *Config, value string) error {
+		period, err := positive(value)
+		c.OTP.Period = int64(period)
+		return err
+	}},
+}
+
+func positive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number", value)
+	}
+	return n, nil
+}
+
+// Load reads the configuration file at path over the built-in configuration.
+// A missing file is only an error when required is set.
+func Load(path string, required bool) (*Config, error) {
+	c := New()
+	file, err := os.Open(expand(path))
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err := c.Parse(file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse reads lines of key = value, grouped by [section] headers. Lines
+// starting with # or ; are comments and values may be double quoted.
+func (c *Config) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	var section string
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected key = value", line)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid quoted value", line)
+			}
+			value = unquoted
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if err := c.set(key, value); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *Config) set(key string, value string) error {
+	for _, s := range settings {
+		if s.key == key {
+			return s.set(c, value)
+		}
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// Env applies the MFA_* environment variables, MFA_DB is handled like SetDB.
+func (c *Config) Env(lookup func(string) (string, bool)) error {
+	if value, ok := lookup("MFA_DB"); ok && value != "" {
+		if err := c.SetDB(value); err != nil {
+			return err
+		}
+	}
+	for _, s := range settings {
+		if value, ok := lookup(s.env); ok && value != "" {
+			if err := s.set(c, value); err != nil {
+				return fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	return nil
+}
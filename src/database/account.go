@@ -1,20 +1,35 @@
 package database
 
 import (
+	"errors"
+
 	"github.com/ozgur-yalcin/mfa/src/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (db *Database) ListAccounts(issuer string, user string) (accounts []models.Account, err error) {
-	db.client.Where(&models.Account{Issuer: issuer, User: user}).Find(&accounts)
+	err = wrap(db.client.Where(&models.Account{Issuer: issuer, User: user}).Find(&accounts).Error)
 	return
 }
 
 func (db *Database) AddAccount(account *models.Account) (err error) {
-	return db.client.Create(account).Error
+	return wrap(db.client.Create(account).Error)
 }
 
 func (db *Database) DelAccount(issuer string, user string) (err error) {
-	return db.client.Where(&models.Account{Issuer: issuer, User: user}).Delete(&models.Account{}).Error
+	return wrap(db.client.Where(&models.Account{Issuer: issuer, User: user}).Delete(&models.Account{}).Error)
+}
+
+func (db *Database) DelAccounts(accounts []models.Account) (err error) {
+	if len(accounts) == 0 {
+		return
+	}
+	ids := make([]uint, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	return wrap(db.client.Delete(&models.Account{}, ids).Error)
 }
 
 func (db *Database) GetAccount(issuer string, user string) (account models.Account) {
@@ -23,5 +38,56 @@ func (db *Database) GetAccount(issuer string, user string) (account models.Accou
 }
 
 func (db *Database) SetAccount(account models.Account) (err error) {
-	return db.client.Save(&account).Error
+	return wrap(db.client.Save(&account).Error)
+}
+
+// NextCode returns the code for the current counter of an HOTP account and
+// advances the stored counter in the same transaction, so a counter is never
+// handed out twice.
+func (db *Database) NextCode(id uint) (account models.Account, code string, err error) {
+	err = db.client.Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if db.Engine() == "postgresql" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := query.First(&account, id).Error; err != nil {
+			return wrap(err)
+		}
+		if account.Mode != "hotp" {
+			return errors.New("account mode is not hotp")
+		}
+		if code, err = account.OTP(); err != nil {
+			return err
+		}
+		if err := advanceCounter(tx, account, account.Counter+1); err != nil {
+			return err
+		}
+		account.Counter++
+		return nil
+	})
+	return
+}
+
+// AdvanceCounter moves the HOTP counter of account forward to counter, failing
+// if the stored counter was changed by someone else in the meantime.
+func (db *Database) AdvanceCounter(account models.Account, counter int64) (err error) {
+	return db.client.Transaction(func(tx *gorm.DB) error {
+		return advanceCounter(tx, account, counter)
+	})
+}
+
+func advanceCounter(tx *gorm.DB, account models.Account, counter int64) error {
+	if counter <= account.Counter {
+		return errors.New("counter can only move forward")
+	}
+	result := tx.Model(&models.Account{}).
+		Where("id = ? AND counter = ?", account.ID, account.Counter).
+		Update("counter", counter)
+	if result.Error != nil {
+		return wrap(result.Error)
+	}
+	if result.RowsAffected != 1 {
+		return errors.New("counter was changed concurrently, try again")
+	}
+	return nil
 }
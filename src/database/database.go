@@ -2,6 +2,9 @@ package database
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/ozgur-yalcin/mfa/src/backend"
 	"github.com/ozgur-yalcin/mfa/src/config"
@@ -10,6 +13,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrDatabase is wrapped by the errors the database engine reports.
+var ErrDatabase = errors.New("database error")
+
 type Database struct {
 	client  *gorm.DB
 	backend backend.Backend
@@ -19,6 +25,11 @@ func (db *Database) Open() (err error) {
 	var client *gorm.DB
 	switch db.backend.Engine() {
 	case "sqlite":
+		if lite, ok := db.backend.(*backend.Sqlite); ok {
+			if err := os.MkdirAll(filepath.Dir(lite.Path()), 0o700); err != nil {
+				return wrap(err)
+			}
+		}
 		client, err = gorm.Open(sqlite.Open(db.backend.Params()), &gorm.Config{})
 	case "postgresql":
 		client, err = gorm.Open(postgres.Open(db.backend.Params()), &gorm.Config{})
@@ -26,7 +37,7 @@ func (db *Database) Open() (err error) {
 		return errors.New("not supported database engine")
 	}
 	if err != nil {
-		return err
+		return wrap(err)
 	}
 	db.client = client
 	return
@@ -49,5 +60,12 @@ func (db *Database) Engine() string {
 }
 
 func (db *Database) AutoMigrate(dst ...any) (err error) {
-	return db.client.AutoMigrate(dst...)
+	return wrap(db.client.AutoMigrate(dst...))
+}
+
+func wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrDatabase, err)
 }
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/src/models"
+)
+
+type MatchMode string
+
+const (
+	// MatchAuto uses regex for /patterns/, glob for patterns with *, ? or [
+	// and substring otherwise.
+	MatchAuto      MatchMode = "auto"
+	MatchExact     MatchMode = "exact"
+	MatchSubstring MatchMode = "substring"
+	MatchGlob      MatchMode = "glob"
+	MatchRegex     MatchMode = "regex"
+)
+
+// Scores of a matching field, lower is better.
+const (
+	scoreEqual = iota
+	scoreEqualFold
+	scorePrefix
+	scoreSubstring
+	scorePattern
+)
+
+func (m *MatchMode) String() string {
+	return string(*m)
+}
+
+func (m *MatchMode) Set(value string) (err error) {
+	*m, err = ParseMatchMode(value)
+	return
+}
+
+func ParseMatchMode(value string) (MatchMode, error) {
+	switch mode := MatchMode(strings.ToLower(value)); mode {
+	case "":
+		return MatchAuto, nil
+	case MatchAuto, MatchExact, MatchSubstring, MatchGlob, MatchRegex:
+		return mode, nil
+	}
+	return "", errors.New("match should be auto, exact, substring, glob or regex")
+}
+
+// Match is an account found by SearchAccounts with the score of the match.
+// Exact is set when the issuer and the user given in the search equal the
+// account's, ignoring case.
+type Match struct {
+	Account models.Account
+	Score   int
+	Exact   bool
+}
+
+type matcher func(value string) (score int, ok bool)
+
+// SearchAccounts returns the accounts whose issuer and user match the
+// patterns, best matches first. Matching ignores case and an empty pattern
+// matches everything.
+func (db *Database) SearchAccounts(issuer string, user string, mode MatchMode) (matches []Match, err error) {
+	var accounts []models.Account
+	if err := db.client.Find(&accounts).Error; err != nil {
+		return nil, wrap(err)
+	}
+	return rank(accounts, issuer, user, mode)
+}
+
+func rank(accounts []models.Account, issuer string, user string, mode MatchMode) (matches []Match, err error) {
+	matchIssuer, err := newMatcher(issuer, mode)
+	if err != nil {
+		return nil, err
+	}
+	matchUser, err := newMatcher(user, mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		issuerScore, ok := matchIssuer(account.Issuer)
+		if !ok {
+			continue
+		}
+		userScore, ok := matchUser(account.User)
+		if !ok {
+			continue
+		}
+		matches = append(matches, Match{
+			Account: account,
+			Score:   issuerScore + userScore,
+			Exact:   issuerScore <= scoreEqualFold && userScore <= scoreEqualFold,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		if issuerA, issuerB := strings.ToLower(a.Account.Issuer), strings.ToLower(b.Account.Issuer); issuerA != issuerB {
+			return issuerA < issuerB
+		}
+		return strings.ToLower(a.Account.User) < strings.ToLower(b.Account.User)
+	})
+	return
+}
+
+func newMatcher(pattern string, mode MatchMode) (matcher, error) {
+	if pattern == "" {
+		return func(string) (int, bool) { return scoreEqual, true }, nil
+	}
+	if mode == MatchAuto || mode == "" {
+		switch {
+		case len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/"):
+			mode, pattern = MatchRegex, pattern[1:len(pattern)-1]
+		case strings.ContainsAny(pattern, "*?["):
+			mode = MatchGlob
+		default:
+			mode = MatchSubstring
+		}
+	}
+	lower := strings.ToLower(pattern)
+	switch mode {
+	case MatchExact:
+		return func(value string) (int, bool) {
+			if value == pattern {
+				return scoreEqual, true
+			}
+			return scoreEqualFold, strings.EqualFold(value, pattern)
+		}, nil
+	case MatchSubstring:
+		return func(value string) (int, bool) {
+			switch folded := strings.ToLower(value); {
+			case value == pattern:
+				return scoreEqual, true
+			case folded == lower:
+				return scoreEqualFold, true
+			case strings.HasPrefix(folded, lower):
+				return scorePrefix, true
+			default:
+				return scoreSubstring, strings.Contains(folded, lower)
+			}
+		}, nil
+	case MatchGlob:
+		if _, err := path.Match(lower, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+		return func(value string) (int, bool) {
+			ok, _ := path.Match(lower, strings.ToLower(value))
+			return scorePattern, ok
+		}, nil
+	case MatchRegex:
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q", pattern)
+		}
+		return func(value string) (int, bool) {
+			return scorePattern, re.MatchString(value)
+		}, nil
+	}
+	return nil, errors.New("match should be auto, exact, substring, glob or regex")
+}
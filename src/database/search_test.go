@@ -0,0 +1,90 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/ozgur-yalcin/mfa/src/models"
+)
+
+var testAccounts = []models.Account{
+	{Issuer: "GitLab", User: "alice"},
+	{Issuer: "GitHub", User: "bob"},
+	{Issuer: "github", User: "alice"},
+	{Issuer: "My GitHub Enterprise", User: "alice"},
+	{Issuer: "Bank", User: "alice@example.com"},
+}
+
+func names(matches []Match) (names []string) {
+	for _, match := range matches {
+		names = append(names, match.Account.Issuer+":"+match.Account.User)
+	}
+	return
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		issuer, user string
+		mode         MatchMode
+		want         []string
+	}{
+		{"GitHub", "", MatchAuto, []string{"GitHub:bob", "github:alice", "My GitHub Enterprise:alice"}},
+		{"git", "", MatchAuto, []string{"github:alice", "GitHub:bob", "GitLab:alice", "My GitHub Enterprise:alice"}},
+		{"git", "ali", MatchAuto, []string{"github:alice", "GitLab:alice", "My GitHub Enterprise:alice"}},
+		{"github", "", MatchExact, []string{"github:alice", "GitHub:bob"}},
+		{"git*", "", MatchAuto, []string{"github:alice", "GitHub:bob", "GitLab:alice"}},
+		{"*github*", "", MatchGlob, []string{"github:alice", "GitHub:bob", "My GitHub Enterprise:alice"}},
+		{"/^git(hub|lab)$/", "", MatchAuto, []string{"github:alice", "GitHub:bob", "GitLab:alice"}},
+		{"", "/@example\\.com$/", MatchAuto, []string{"Bank:alice@example.com"}},
+		{"/x/", "", MatchSubstring, nil},
+	}
+	for _, test := range tests {
+		matches, err := rank(testAccounts, test.issuer, test.user, test.mode)
+		if err != nil {
+			t.Fatalf("rank(%q, %q, %s): %v", test.issuer, test.user, test.mode, err)
+		}
+		got := names(matches)
+		if len(got) != len(test.want) {
+			t.Errorf("rank(%q, %q, %s) = %q, want %q", test.issuer, test.user, test.mode, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("rank(%q, %q, %s) = %q, want %q", test.issuer, test.user, test.mode, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRankExact(t *testing.T) {
+	matches, err := rank(testAccounts, "github", "", MatchAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range matches {
+		if exact := match.Account.Issuer != "My GitHub Enterprise"; match.Exact != exact {
+			t.Errorf("%s: exact = %v, want %v", match.Account.Issuer, match.Exact, exact)
+		}
+	}
+}
+
+func TestInvalidPatterns(t *testing.T) {
+	if _, err := rank(testAccounts, "/(/", "", MatchAuto); err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+	if _, err := rank(testAccounts, "[", "", MatchGlob); err == nil {
+		t.Error("expected an error for an invalid glob")
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for value, want := range map[string]MatchMode{"": MatchAuto, "Exact": MatchExact, "glob": MatchGlob, "regex": MatchRegex} {
+		got, err := ParseMatchMode(value)
+		if err != nil || got != want {
+			t.Errorf("ParseMatchMode(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseMatchMode("fuzzy"); err == nil {
+		t.Error("expected an error for fuzzy")
+	}
+}
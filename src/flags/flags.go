@@ -0,0 +1,311 @@
+// Package flags parses command lines the GNU way: flags may follow
+// positional arguments, long flags are written --name or --name=value,
+// single letter shorthands can be combined as in -ab and -- ends the flags.
+package flags
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrHelp is returned by Parse for -h and --help when no such flag is
+// defined.
+var ErrHelp = flag.ErrHelp
+
+// Error is returned by Parse for unknown flags, missing or invalid values and
+// unexpected arguments.
+type Error struct {
+	message string
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func errorf(format string, args ...any) error {
+	return &Error{message: fmt.Sprintf(format, args...)}
+}
+
+// Flag is an option with a long name and an optional single letter
+// shorthand.
+type Flag struct {
+	Name     string
+	Short    string
+	Usage    string
+	Value    flag.Value
+	DefValue string
+	// Inherited is set on flags added from another set by AddFlagSet.
+	Inherited bool
+}
+
+// IsBool reports whether the flag is set without a value.
+func (f *Flag) IsBool() bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+type FlagSet struct {
+	name         string
+	values       *flag.FlagSet
+	flags        map[string]*Flag
+	shorthands   map[string]*Flag
+	changed      map[string]bool
+	args         []string
+	maxArgs      int
+	interspersed bool
+}
+
+// New returns an empty flag set that accepts any number of arguments and
+// flags between them.
+func New(name string) *FlagSet {
+	return &FlagSet{
+		name:         name,
+		values:       flag.NewFlagSet(name, flag.ContinueOnError),
+		flags:        make(map[string]*Flag),
+		shorthands:   make(map[string]*Flag),
+		changed:      make(map[string]bool),
+		maxArgs:      -1,
+		interspersed: true,
+	}
+}
+
+func (f *FlagSet) Name() string {
+	return f.name
+}
+
+// SetMaxArgs makes Parse reject more than n arguments, a negative n allows
+// any number.
+func (f *FlagSet) SetMaxArgs(n int) {
+	f.maxArgs = n
+}
+
+// SetInterspersed sets whether flags may follow arguments. When they may
+// not, parsing stops at the first argument and the rest is left in Args.
+func (f *FlagSet) SetInterspersed(interspersed bool) {
+	f.interspersed = interspersed
+}
+
+func (f *FlagSet) StringVar(p *string, name string, short string, value string, usage string) {
+	f.values.StringVar(p, name, value, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) BoolVar(p *bool, name string, short string, value bool, usage string) {
+	f.values.BoolVar(p, name, value, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) IntVar(p *int, name string, short string, value int, usage string) {
+	f.values.IntVar(p, name, value, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) Int64Var(p *int64, name string, short string, value int64, usage string) {
+	f.values.Int64Var(p, name, value, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) Float64Var(p *float64, name string, short string, value float64, usage string) {
+	f.values.Float64Var(p, name, value, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) DurationVar(p *time.Duration, name string, short string, value time.Duration, usage string) {
+	f.values.DurationVar(p, name, value, usage)
+	f.add(name, short)
+}
+
+// Var defines a flag with a custom value, its current value is the default.
+func (f *FlagSet) Var(value flag.Value, name string, short string, usage string) {
+	f.values.Var(value, name, usage)
+	f.add(name, short)
+}
+
+func (f *FlagSet) add(name string, short string) {
+	v := f.values.Lookup(name)
+	fl := &Flag{Name: name, Short: short, Usage: v.Usage, Value: v.Value, DefValue: v.DefValue}
+	if short != "" {
+		if utf8.RuneCountInString(short) != 1 {
+			panic(fmt.Sprintf("%s: shorthand %q of --%s is not a single letter", f.name, short, name))
+		}
+		if _, ok := f.shorthands[short]; ok {
+			panic(fmt.Sprintf("%s: shorthand -%s redefined", f.name, short))
+		}
+		f.shorthands[short] = fl
+	}
+	f.flags[name] = fl
+}
+
+// AddFlagSet adds the flags of other that are not defined yet, sharing their
+// values. A shorthand already in use is left out.
+func (f *FlagSet) AddFlagSet(other *FlagSet) {
+	other.VisitAll(func(fl *Flag) {
+		if _, ok := f.flags[fl.Name]; ok {
+			return
+		}
+		inherited := *fl
+		inherited.Inherited = true
+		if _, ok := f.shorthands[fl.Short]; ok {
+			inherited.Short = ""
+		} else if fl.Short != "" {
+			f.shorthands[fl.Short] = &inherited
+		}
+		f.flags[fl.Name] = &inherited
+	})
+}
+
+// Lookup returns the flag with the long name or shorthand name.
+func (f *FlagSet) Lookup(name string) *Flag {
+	if fl, ok := f.flags[name]; ok {
+		return fl
+	}
+	return f.shorthands[name]
+}
+
+// VisitAll calls fn for every flag sorted by long name.
+func (f *FlagSet) VisitAll(fn func(*Flag)) {
+	names := make([]string, 0, len(f.flags))
+	for name := range f.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn(f.flags[name])
+	}
+}
+
+// Changed reports whether the flag with the long name was given by the last
+// Parse.
+func (f *FlagSet) Changed(name string) bool {
+	return f.changed[name]
+}
+
+func (f *FlagSet) Args() []string {
+	return f.args
+}
+
+func (f *FlagSet) Arg(i int) string {
+	if i < 0 || i >= len(f.args) {
+		return ""
+	}
+	return f.args[i]
+}
+
+func (f *FlagSet) NArg() int {
+	return len(f.args)
+}
+
+func (f *FlagSet) set(fl *Flag, written string, value string) error {
+	if err := fl.Value.Set(value); err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			return err
+		}
+		return errorf("invalid value %q for %s: %v", value, written, err)
+	}
+	f.changed[fl.Name] = true
+	return nil
+}
+
+// Parse parses the flags in args, which should not include the command
+// name.
+func (f *FlagSet) Parse(args []string) error {
+	f.args = nil
+	f.changed = make(map[string]bool)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			f.args = append(f.args, args[i+1:]...)
+			return f.checkArgs()
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			fl := f.flags[name]
+			if fl == nil {
+				if name == "help" {
+					return ErrHelp
+				}
+				return errorf("unknown flag --%s", name)
+			}
+			if fl.IsBool() {
+				if !hasValue {
+					value = "true"
+				}
+			} else if !hasValue {
+				if i+1 >= len(args) {
+					return errorf("flag --%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if err := f.set(fl, "--"+name, value); err != nil {
+				return err
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			next, err := f.parseShorthands(arg, args[i+1:])
+			if err != nil {
+				return err
+			}
+			i += next
+		case !f.interspersed:
+			f.args = append(f.args, args[i:]...)
+			return f.checkArgs()
+		default:
+			f.args = append(f.args, arg)
+		}
+	}
+	return f.checkArgs()
+}
+
+// parseShorthands parses a group of shorthands such as -ab, -m hotp or
+// -mhotp and returns how many of the following arguments were used as a
+// value.
+func (f *FlagSet) parseShorthands(arg string, rest []string) (int, error) {
+	// -mode is more likely a long flag with one dash than -m ode
+	if name, _, _ := strings.Cut(arg[1:], "="); utf8.RuneCountInString(name) > 1 && f.flags[name] != nil {
+		return 0, errorf("flag %s should be written -%s", arg, arg)
+	}
+	shorthands := arg[1:]
+	for len(shorthands) > 0 {
+		r, size := utf8.DecodeRuneInString(shorthands)
+		short := string(r)
+		shorthands = shorthands[size:]
+		fl := f.shorthands[short]
+		if fl == nil {
+			if short == "h" {
+				return 0, ErrHelp
+			}
+			return 0, errorf("unknown shorthand -%s in %s", short, arg)
+		}
+		written := "-" + short
+		if fl.IsBool() {
+			if value, ok := strings.CutPrefix(shorthands, "="); ok {
+				return 0, f.set(fl, written, value)
+			}
+			if err := f.set(fl, written, "true"); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if shorthands != "" {
+			return 0, f.set(fl, written, strings.TrimPrefix(shorthands, "="))
+		}
+		if len(rest) == 0 {
+			return 0, errorf("flag %s needs a value", written)
+		}
+		return 1, f.set(fl, written, rest[0])
+	}
+	return 0, nil
+}
+
+func (f *FlagSet) checkArgs() error {
+	if f.maxArgs >= 0 && len(f.args) > f.maxArgs {
+		return errorf("unexpected argument %q", f.args[f.maxArgs])
+	}
+	return nil
+}
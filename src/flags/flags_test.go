@@ -0,0 +1,137 @@
+package flags
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testFlags struct {
+	mode    string
+	digits  int
+	hotp    bool
+	verbose bool
+	clear   time.Duration
+}
+
+func newTestSet() (*FlagSet, *testFlags) {
+	v := &testFlags{}
+	fs := New("test")
+	fs.StringVar(&v.mode, "mode", "m", "totp", "mode")
+	fs.IntVar(&v.digits, "digits", "l", 6, "digits")
+	fs.BoolVar(&v.hotp, "hotp", "", false, "hotp")
+	fs.BoolVar(&v.verbose, "verbose", "v", false, "verbose")
+	fs.DurationVar(&v.clear, "clear", "", 45*time.Second, "clear")
+	return fs, v
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		args []string
+		want testFlags
+		rest []string
+	}{
+		{nil, testFlags{mode: "totp", digits: 6, clear: 45 * time.Second}, nil},
+		{[]string{"GitHub", "SECRET", "-m", "hotp"}, testFlags{mode: "hotp", digits: 6, clear: 45 * time.Second}, []string{"GitHub", "SECRET"}},
+		{[]string{"--mode=steam", "--digits", "8", "a"}, testFlags{mode: "steam", digits: 8, clear: 45 * time.Second}, []string{"a"}},
+		{[]string{"-vl8", "-mhotp"}, testFlags{mode: "hotp", digits: 8, verbose: true, clear: 45 * time.Second}, nil},
+		{[]string{"-l=7", "--hotp", "--clear=0s"}, testFlags{mode: "totp", digits: 7, hotp: true}, nil},
+		{[]string{"a", "--", "-m", "--hotp"}, testFlags{mode: "totp", digits: 6, clear: 45 * time.Second}, []string{"a", "-m", "--hotp"}},
+		{[]string{"-", "--verbose=false"}, testFlags{mode: "totp", digits: 6, clear: 45 * time.Second}, []string{"-"}},
+	}
+	for _, test := range tests {
+		fs, v := newTestSet()
+		if err := fs.Parse(test.args); err != nil {
+			t.Errorf("Parse(%q): %v", test.args, err)
+			continue
+		}
+		if *v != test.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", test.args, *v, test.want)
+		}
+		if !reflect.DeepEqual(fs.Args(), test.rest) {
+			t.Errorf("Parse(%q) args = %q, want %q", test.args, fs.Args(), test.rest)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"--unknown"},
+		{"-x"},
+		{"-mode", "hotp"},
+		{"--digits", "six"},
+		{"--digits"},
+		{"-m"},
+		{"--hotp=maybe"},
+	} {
+		fs, _ := newTestSet()
+		var e *Error
+		if err := fs.Parse(args); !errors.As(err, &e) {
+			t.Errorf("Parse(%q) = %v, want a parse error", args, err)
+		}
+	}
+	for _, args := range [][]string{{"-h"}, {"a", "--help"}, {"-vh"}} {
+		fs, _ := newTestSet()
+		if err := fs.Parse(args); err != ErrHelp {
+			t.Errorf("Parse(%q) = %v, want ErrHelp", args, err)
+		}
+	}
+}
+
+func TestMaxArgs(t *testing.T) {
+	fs, _ := newTestSet()
+	fs.SetMaxArgs(2)
+	if err := fs.Parse([]string{"a", "b"}); err != nil {
+		t.Error(err)
+	}
+	err := fs.Parse([]string{"a", "b", "c"})
+	if err == nil || err.Error() != `unexpected argument "c"` {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestInterspersed(t *testing.T) {
+	fs, v := newTestSet()
+	fs.SetInterspersed(false)
+	if err := fs.Parse([]string{"-v", "list", "-m", "hotp"}); err != nil {
+		t.Fatal(err)
+	}
+	if !v.verbose || v.mode != "totp" || !reflect.DeepEqual(fs.Args(), []string{"list", "-m", "hotp"}) {
+		t.Errorf("got %+v, args %q", *v, fs.Args())
+	}
+}
+
+func TestChanged(t *testing.T) {
+	fs, _ := newTestSet()
+	if err := fs.Parse([]string{"-m", "totp"}); err != nil {
+		t.Fatal(err)
+	}
+	if !fs.Changed("mode") || fs.Changed("digits") {
+		t.Error("unexpected changed flags")
+	}
+	if fs.Lookup("m") != fs.Lookup("mode") || fs.Lookup("m") == nil {
+		t.Error("shorthand lookup failed")
+	}
+}
+
+func TestAddFlagSet(t *testing.T) {
+	var output, db string
+	parent := New("parent")
+	parent.StringVar(&output, "output", "o", "text", "output")
+	parent.StringVar(&db, "db", "m", "", "db")
+	fs, v := newTestSet()
+	fs.AddFlagSet(parent)
+	if err := fs.Parse([]string{"-o", "json", "--db=x.db", "-m", "hotp"}); err != nil {
+		t.Fatal(err)
+	}
+	if output != "json" || db != "x.db" || v.mode != "hotp" {
+		t.Errorf("got output %q, db %q, mode %q", output, db, v.mode)
+	}
+	if f := fs.Lookup("db"); f == nil || !f.Inherited || f.Short != "" {
+		t.Errorf("unexpected inherited flag %+v", f)
+	}
+	if fs.Lookup("mode").Inherited {
+		t.Error("own flag marked inherited")
+	}
+}
@@ -1,5 +1,7 @@
 package initialize
 
-func Init() {
-	DB()
+// Init prepares the database, creating its directory and tables when they do
+// not exist yet.
+func Init() error {
+	return DB()
 }
@@ -1,9 +1,15 @@
 package models
 
 import (
+	"encoding/base32"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/ozgur-yalcin/mfa/otp"
+	"github.com/ozgur-yalcin/mfa/otp/uri"
 )
 
 type Account struct {
@@ -16,17 +22,150 @@ type Account struct {
 	Digits  int    `json:"digits"`
 	Period  int64  `json:"period"`
 	Counter int64  `json:"counter"`
+	Suite   string `json:"suite"`
 }
 
+// OTP returns the current code of a HOTP, TOTP or Steam Guard account.
 func (a Account) OTP() (code string, err error) {
+	if a.Mode == "ocra" {
+		return code, errors.New("ocra accounts require a challenge")
+	}
+	return a.GenerateAt(time.Now(), otp.OCRAInput{})
+}
+
+// GenerateAt returns the code of the account at the given time. HOTP codes
+// only depend on the counter, and OCRA codes answer the challenge of input
+// with the suite and counter of the account.
+func (a Account) GenerateAt(at time.Time, input otp.OCRAInput) (code string, err error) {
+	switch a.Mode {
+	case "hotp":
+		hotp, err := otp.NewHOTP(a.Hash, a.Digits, a.Counter)
+		if err != nil {
+			return code, err
+		}
+		return hotp.GeneratePassCode(a.Secret)
+	case "totp":
+		totp, err := otp.NewTOTP(a.Hash, a.Digits, a.Period)
+		if err != nil {
+			return code, err
+		}
+		return totp.GenerateAt(a.Secret, at)
+	case "steam":
+		return otp.NewSteam(a.Period).GenerateAt(a.Secret, at)
+	case "ocra":
+		ocra, err := otp.NewOCRA(a.Suite)
+		if err != nil {
+			return code, err
+		}
+		input.Counter = a.Counter
+		input.Time = at
+		return ocra.GeneratePassCode(a.Secret, input)
+	}
+	return code, errors.New("mode should be hotp, totp, steam or ocra")
+}
+
+func (a Account) Validate(code string, window int) (ok bool, err error) {
 	if a.Mode == "hotp" {
-		hotp := otp.NewHOTP(a.Hash, a.Digits, a.Counter)
-		code, err = hotp.GeneratePassCode(a.Secret)
+		hotp, err := otp.NewHOTP(a.Hash, a.Digits, a.Counter)
+		if err != nil {
+			return ok, err
+		}
+		return hotp.Validate(a.Secret, code, window)
 	} else if a.Mode == "totp" {
-		totp := otp.NewTOTP(a.Hash, a.Digits, a.Period)
-		code, err = totp.GeneratePassCode(a.Secret)
+		totp, err := otp.NewTOTP(a.Hash, a.Digits, a.Period)
+		if err != nil {
+			return ok, err
+		}
+		return totp.Validate(a.Secret, code, window)
+	} else if a.Mode == "steam" {
+		steam := otp.NewSteam(a.Period)
+		ok, err = steam.Validate(a.Secret, code, window)
+	} else if a.Mode == "ocra" {
+		return ok, errors.New("ocra accounts require a challenge")
 	} else {
-		return code, errors.New("mode should be hotp or totp")
+		return ok, errors.New("mode should be hotp, totp, steam or ocra")
 	}
 	return
 }
+
+// Respond answers an OCRA challenge using the stored suite and counter.
+func (a Account) Respond(input otp.OCRAInput) (code string, err error) {
+	if a.Mode != "ocra" {
+		return code, errors.New("account mode is not ocra")
+	}
+	return a.GenerateAt(time.Now(), input)
+}
+
+// Key returns the otpauth key URI parameters used to provision the account.
+func (a Account) Key() (*uri.Key, error) {
+	secret, err := otp.DecodeSecret(a.Secret)
+	if err != nil {
+		return nil, err
+	}
+	key := &uri.Key{
+		Type:      a.Mode,
+		Issuer:    a.Issuer,
+		Account:   a.User,
+		Secret:    base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+		Algorithm: a.Hash,
+		Digits:    a.Digits,
+		Period:    a.Period,
+		Counter:   a.Counter,
+		Params:    url.Values{},
+	}
+	switch a.Mode {
+	case "hotp":
+		key.Period = 0
+	case "totp":
+		key.Counter = 0
+	case "steam":
+		key.Type = "totp"
+		key.Counter = 0
+		key.Params.Set("encoder", "steam")
+	default:
+		return nil, fmt.Errorf("%s accounts cannot be exported as otpauth uri", a.Mode)
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// URI returns the otpauth:// key URI used to provision the account.
+func (a Account) URI() (string, error) {
+	key, err := a.Key()
+	if err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+// SetKey copies the parameters present in key onto the account, the fields
+// of absent parameters keep their current values.
+func (a *Account) SetKey(key *uri.Key) {
+	a.Mode = key.Type
+	a.Issuer = key.Issuer
+	a.User = key.Account
+	if a.Issuer == "" {
+		a.Issuer = key.Account
+		a.User = ""
+	}
+	a.Secret = key.Secret
+	if key.Algorithm != "" {
+		a.Hash = key.Algorithm
+	}
+	if key.Digits != 0 {
+		a.Digits = key.Digits
+	}
+	if key.Type == "totp" && key.Period != 0 {
+		a.Period = key.Period
+	}
+	if key.Type == "hotp" {
+		a.Counter = key.Counter
+	}
+	if key.Type == "totp" && (strings.EqualFold(key.Params.Get("encoder"), "steam") || strings.EqualFold(key.Issuer, "steam")) {
+		a.Mode = "steam"
+		a.Hash = "SHA1"
+		a.Digits = 5
+	}
+}
@@ -0,0 +1,35 @@
+package output
+
+// Class groups errors so scripts can tell them apart by the exit code.
+type Class string
+
+const (
+	ClassError        Class = "error"
+	ClassUsage        Class = "usage"
+	ClassNotFound     Class = "not_found"
+	ClassAmbiguous    Class = "ambiguous"
+	ClassExists       Class = "exists"
+	ClassInvalidCode  Class = "invalid_code"
+	ClassInvalidInput Class = "invalid_input"
+	ClassDatabase     Class = "database"
+)
+
+var exitCodes = map[Class]int{
+	ClassError:        1,
+	ClassUsage:        2,
+	ClassNotFound:     3,
+	ClassAmbiguous:    4,
+	ClassExists:       5,
+	ClassInvalidCode:  6,
+	ClassInvalidInput: 7,
+	ClassDatabase:     8,
+}
+
+// ExitCode returns the process exit status for the class, unknown classes
+// exit with 1.
+func (c Class) ExitCode() int {
+	if code, ok := exitCodes[c]; ok {
+		return code
+	}
+	return 1
+}
@@ -0,0 +1,275 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+	FormatRaw  Format = "raw"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatJSON, FormatYAML, FormatCSV, FormatRaw:
+		return format, nil
+	}
+	return "", errors.New("output should be text, json, yaml, csv or raw")
+}
+
+func (f *Format) String() string {
+	return string(*f)
+}
+
+func (f *Format) Set(value string) (err error) {
+	*f, err = ParseFormat(value)
+	return
+}
+
+// Structured reports whether the format is meant for scripts rather than
+// people.
+func (f Format) Structured() bool {
+	return f != FormatText
+}
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Account is one account a command worked on. Fields a command does not know
+// about are left empty and omitted.
+type Account struct {
+	Issuer    string `json:"issuer,omitempty"`
+	User      string `json:"user,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Digits    int    `json:"digits,omitempty"`
+	Period    int64  `json:"period,omitempty"`
+	Counter   *int64 `json:"counter,omitempty"`
+	Suite     string `json:"suite,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Remaining int64  `json:"remaining,omitempty"`
+	Secret    string `json:"secret,omitempty"`
+	URI       string `json:"uri,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Change is a field of an account a command changed, the values of the
+// secret are left out.
+type Change struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+type Error struct {
+	Class   Class  `json:"class"`
+	Message string `json:"message"`
+	Exit    int    `json:"exit"`
+}
+
+type Result struct {
+	Status   string    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	Accounts []Account `json:"accounts,omitempty"`
+	Changes  []Change  `json:"changes,omitempty"`
+	URIs     []string  `json:"uris,omitempty"`
+	Error    *Error    `json:"error,omitempty"`
+}
+
+// Failure builds the result reported for err.
+func Failure(class Class, err error) Result {
+	return Result{
+		Status: StatusError,
+		Error: &Error{
+			Class:   class,
+			Message: err.Error(),
+			Exit:    class.ExitCode(),
+		},
+	}
+}
+
+// Write encodes result in format. The text format only prints the message,
+// commands with richer human readable output print it themselves.
+func Write(w io.Writer, format Format, result Result) (err error) {
+	if result.Status == "" {
+		result.Status = StatusOK
+	}
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case FormatYAML:
+		return writeYAML(w, result)
+	case FormatCSV:
+		return writeCSV(w, result)
+	case FormatRaw:
+		return writeRaw(w, result)
+	default:
+		if result.Error != nil {
+			_, err = fmt.Fprintln(w, result.Error.Message)
+		} else if result.Message != "" {
+			_, err = fmt.Fprintln(w, result.Message)
+		}
+		return err
+	}
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+// fields lists the set fields of an account in a stable order, numbers are
+// kept unquoted so they stay numbers in YAML.
+func (a Account) fields() (fields []field) {
+	text := func(name, value string) {
+		if value != "" {
+			fields = append(fields, field{name, strconv.Quote(value)})
+		}
+	}
+	number := func(name string, value int64) {
+		if value != 0 {
+			fields = append(fields, field{name, strconv.FormatInt(value, 10)})
+		}
+	}
+	text("issuer", a.Issuer)
+	text("user", a.User)
+	text("mode", a.Mode)
+	text("hash", a.Hash)
+	number("digits", int64(a.Digits))
+	number("period", a.Period)
+	if a.Counter != nil {
+		fields = append(fields, field{"counter", strconv.FormatInt(*a.Counter, 10)})
+	}
+	text("suite", a.Suite)
+	text("code", a.Code)
+	number("remaining", a.Remaining)
+	text("secret", a.Secret)
+	text("uri", a.URI)
+	text("error", a.Error)
+	return
+}
+
+func writeYAML(w io.Writer, result Result) (err error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "status: %s\n", result.Status)
+	if result.Message != "" {
+		fmt.Fprintf(&b, "message: %s\n", strconv.Quote(result.Message))
+	}
+	if len(result.Accounts) > 0 {
+		b.WriteString("accounts:\n")
+		for _, account := range result.Accounts {
+			fields := account.fields()
+			if len(fields) == 0 {
+				b.WriteString("  - {}\n")
+			}
+			for i, f := range fields {
+				prefix := "    "
+				if i == 0 {
+					prefix = "  - "
+				}
+				fmt.Fprintf(&b, "%s%s: %s\n", prefix, f.name, f.value)
+			}
+		}
+	}
+	if len(result.Changes) > 0 {
+		b.WriteString("changes:\n")
+		for _, change := range result.Changes {
+			fmt.Fprintf(&b, "  - field: %s\n", strconv.Quote(change.Field))
+			if change.Before != "" {
+				fmt.Fprintf(&b, "    before: %s\n", strconv.Quote(change.Before))
+			}
+			if change.After != "" {
+				fmt.Fprintf(&b, "    after: %s\n", strconv.Quote(change.After))
+			}
+		}
+	}
+	if len(result.URIs) > 0 {
+		b.WriteString("uris:\n")
+		for _, uri := range result.URIs {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(uri))
+		}
+	}
+	if result.Error != nil {
+		b.WriteString("error:\n")
+		fmt.Fprintf(&b, "  class: %s\n", result.Error.Class)
+		fmt.Fprintf(&b, "  message: %s\n", strconv.Quote(result.Error.Message))
+		fmt.Fprintf(&b, "  exit: %d\n", result.Error.Exit)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeCSV writes one row per account. Results without accounts, such as
+// errors, are written as a single status row.
+func writeCSV(w io.Writer, result Result) (err error) {
+	writer := csv.NewWriter(w)
+	switch {
+	case result.Error != nil:
+		writer.Write([]string{"status", "class", "message"})
+		writer.Write([]string{result.Status, string(result.Error.Class), result.Error.Message})
+	case len(result.Accounts) > 0:
+		writer.Write([]string{"issuer", "user", "mode", "code", "remaining", "error"})
+		for _, account := range result.Accounts {
+			var remaining string
+			if account.Remaining > 0 {
+				remaining = strconv.FormatInt(account.Remaining, 10)
+			}
+			writer.Write([]string{account.Issuer, account.User, account.Mode, account.Code, remaining, account.Error})
+		}
+	case len(result.URIs) > 0:
+		writer.Write([]string{"uri"})
+		for _, uri := range result.URIs {
+			writer.Write([]string{uri})
+		}
+	default:
+		writer.Write([]string{"status", "message"})
+		writer.Write([]string{result.Status, result.Message})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeRaw writes only the codes, one per line, so the output of a single
+// account can be used as is. Without codes the URIs or the message are
+// written instead.
+func writeRaw(w io.Writer, result Result) (err error) {
+	if result.Error != nil {
+		_, err = fmt.Fprintln(w, result.Error.Message)
+		return err
+	}
+	var lines []string
+	for _, account := range result.Accounts {
+		if account.Code != "" {
+			lines = append(lines, account.Code)
+		}
+	}
+	if len(lines) == 0 {
+		lines = result.URIs
+	}
+	if len(lines) == 0 && result.Message != "" {
+		lines = []string{result.Message}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func testResult() Result {
+	counter := int64(3)
+	return Result{
+		Message: "done",
+		Accounts: []Account{
+			{Issuer: "GitHub", User: "alice", Mode: "totp", Code: "123456", Remaining: 12},
+			{Issuer: "Bank, Inc", User: "bob", Mode: "hotp", Counter: &counter, Error: "generate failed"},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, want := range map[string]Format{"": FormatText, "JSON": FormatJSON, "yaml": FormatYAML, "csv": FormatCSV, "raw": FormatRaw} {
+		got, err := ParseFormat(value)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected an error for xml")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatJSON, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	var got Result
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusOK || len(got.Accounts) != 2 || got.Accounts[0].Remaining != 12 || *got.Accounts[1].Counter != 3 {
+		t.Errorf("unexpected result %+v", got)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatYAML, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	want := `status: ok
+message: "done"
+accounts:
+  - issuer: "GitHub"
+    user: "alice"
+    mode: "totp"
+    code: "123456"
+    remaining: 12
+  - issuer: "Bank, Inc"
+    user: "bob"
+    mode: "hotp"
+    counter: 3
+    error: "generate failed"
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteYAMLChanges(t *testing.T) {
+	var out bytes.Buffer
+	result := Result{
+		Message: "account updated successfully",
+		Changes: []Change{{Field: "hash", Before: "SHA1", After: "SHA256"}, {Field: "secret"}},
+	}
+	if err := Write(&out, FormatYAML, result); err != nil {
+		t.Fatal(err)
+	}
+	want := `status: ok
+message: "account updated successfully"
+changes:
+  - field: "hash"
+    before: "SHA1"
+    after: "SHA256"
+  - field: "secret"
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatCSV, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	want := "issuer,user,mode,code,remaining,error\n" +
+		"GitHub,alice,totp,123456,12,\n" +
+		"\"Bank, Inc\",bob,hotp,,,generate failed\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatRaw, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "123456\n" {
+		t.Errorf("got %q, want %q", out.String(), "123456\n")
+	}
+}
+
+func TestFailure(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatCSV, Failure(ClassNotFound, errors.New("account not found"))); err != nil {
+		t.Fatal(err)
+	}
+	if want := "status,class,message\nerror,not_found,account not found\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+	if ClassNotFound.ExitCode() != 3 || Class("unknown").ExitCode() != 1 {
+		t.Error("unexpected exit codes")
+	}
+}
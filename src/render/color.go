@@ -0,0 +1,61 @@
+package render
+
+import (
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+var (
+	Black = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	White = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// ParseColor parses a #RRGGBB or #RGB hex colour, the # is optional.
+func ParseColor(value string) (color.RGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	rgb, err := hex.DecodeString(value)
+	if err != nil || len(rgb) != 3 {
+		return color.RGBA{}, errors.New("color should be in #RRGGBB format")
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+}
+
+type coloredMatrix struct {
+	*lib.BitMatrix
+	palette color.Palette
+}
+
+// Colorize returns the matrix as an image with dark modules in foreground
+// and light modules in background.
+func Colorize(matrix *lib.BitMatrix, foreground color.Color, background color.Color) image.Image {
+	return &coloredMatrix{
+		BitMatrix: matrix,
+		palette:   color.Palette{background, foreground},
+	}
+}
+
+func (img *coloredMatrix) ColorModel() color.Model {
+	return img.palette
+}
+
+func (img *coloredMatrix) At(x, y int) color.Color {
+	if img.Get(x, y) {
+		return img.palette[1]
+	}
+	return img.palette[0]
+}
+
+func (img *coloredMatrix) ColorIndexAt(x, y int) uint8 {
+	if img.Get(x, y) {
+		return 1
+	}
+	return 0
+}
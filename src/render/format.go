@@ -0,0 +1,70 @@
+package render
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+type Format string
+
+const (
+	FormatAuto   Format = "auto"
+	FormatBlocks Format = "blocks"
+	FormatSixel  Format = "sixel"
+	FormatKitty  Format = "kitty"
+	FormatITerm  Format = "iterm"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "":
+		return FormatAuto, nil
+	case FormatAuto, FormatBlocks, FormatSixel, FormatKitty, FormatITerm:
+		return format, nil
+	}
+	return "", errors.New("qr format should be auto, blocks, sixel, kitty or iterm")
+}
+
+// DetectFormat guesses the best supported format from the environment
+// variables terminals set. Inside tmux or screen the image protocols need
+// passthrough, so it falls back to blocks there.
+func DetectFormat() Format {
+	return detectFormat(os.Getenv)
+}
+
+func detectFormat(getenv func(string) string) Format {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux"):
+		return FormatBlocks
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return FormatKitty
+	case program == "iTerm.app" || getenv("LC_TERMINAL") == "iTerm2" || program == "WezTerm":
+		return FormatITerm
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "mlterm") || strings.HasPrefix(term, "foot") || term == "yaft-256color":
+		return FormatSixel
+	}
+	return FormatBlocks
+}
+
+// Display draws the matrix in the given format. Invert only applies to
+// blocks, the image formats always draw dark modules on a white background.
+func Display(w io.Writer, matrix *lib.BitMatrix, format Format, invert bool) error {
+	if format == FormatAuto || format == "" {
+		format = DetectFormat()
+	}
+	switch format {
+	case FormatSixel:
+		return Sixel(w, matrix, DefaultScale)
+	case FormatKitty:
+		return Kitty(w, matrix, DefaultScale)
+	case FormatITerm:
+		return ITerm(w, matrix, DefaultScale)
+	}
+	return Terminal(w, matrix, invert)
+}
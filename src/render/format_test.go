@@ -0,0 +1,77 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Format
+	}{
+		{map[string]string{}, FormatBlocks},
+		{map[string]string{"TERM": "xterm-kitty"}, FormatKitty},
+		{map[string]string{"KITTY_WINDOW_ID": "1", "TERM": "xterm-256color"}, FormatKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, FormatITerm},
+		{map[string]string{"LC_TERMINAL": "iTerm2"}, FormatITerm},
+		{map[string]string{"TERM": "foot"}, FormatSixel},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-0/default,1,0"}, FormatBlocks},
+	}
+	for _, tt := range tests {
+		if got := detectFormat(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("X \n X\n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		format Format
+		prefix string
+		suffix string
+	}{
+		{FormatSixel, "\x1bP0;1;0q\"1;1;12;12#0;2;0;0;0#1;2;100;100;100#0!6~!6?$#1!6?!6~-", "\x1b\\\n"},
+		{FormatKitty, "\x1b_Ga=T,f=100,m=0;iVBORw0KGgo", "\x1b\\\n"},
+		{FormatITerm, "\x1b]1337;File=inline=1;size=", "\a\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := Display(&out, matrix, tt.format, false); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out.String(), tt.prefix) || !strings.HasSuffix(out.String(), tt.suffix) {
+			t.Errorf("%s: got %q", tt.format, out.String())
+		}
+	}
+}
+
+func TestITermSize(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("X \n X\n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := ITerm(&out, matrix, DefaultScale); err != nil {
+		t.Fatal(err)
+	}
+	_, rest, _ := strings.Cut(out.String(), "size=")
+	size, rest, _ := strings.Cut(rest, ";")
+	_, data, _ := strings.Cut(rest, ":")
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(data, "\a\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != strconv.Itoa(len(decoded)) {
+		t.Errorf("got size %s, want %d", size, len(decoded))
+	}
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"io"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+const (
+	// DefaultScale is the size of one module in pixels for the image protocols.
+	DefaultScale = 6
+
+	kittyChunkSize = 4096
+)
+
+// Sixel draws the matrix as a DEC Sixel image with dark modules on a white
+// background, scale pixels per module.
+func Sixel(w io.Writer, matrix *lib.BitMatrix, scale int) error {
+	img, err := scaled(matrix, scale)
+	if err != nil {
+		return err
+	}
+	width, height := img.GetWidth(), img.GetHeight()
+	writer := bufio.NewWriter(w)
+	// P2=1 leaves pixels without a sixel transparent, so only dark and
+	// light are painted explicitly through color registers 0 and 1
+	fmt.Fprintf(writer, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	writer.WriteString("#0;2;0;0;0#1;2;100;100;100")
+	for band := 0; band < height; band += 6 {
+		for color, dark := range []bool{true, false} {
+			fmt.Fprintf(writer, "#%d", color)
+			var last byte
+			run := 0
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(writer, "!%d%c", run, last)
+				case run > 0:
+					writer.Write(bytes.Repeat([]byte{last}, run))
+				}
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for i := 0; i < 6 && band+i < height; i++ {
+					if img.Get(x, band+i) == dark {
+						bits |= 1 << i
+					}
+				}
+				char := 63 + bits
+				if char == last {
+					run++
+					continue
+				}
+				flush()
+				last, run = char, 1
+			}
+			flush()
+			if color == 0 {
+				writer.WriteString("$")
+			}
+		}
+		writer.WriteString("-")
+	}
+	writer.WriteString("\x1b\\\n")
+	return writer.Flush()
+}
+
+// Kitty draws the matrix through the kitty terminal graphics protocol.
+func Kitty(w io.Writer, matrix *lib.BitMatrix, scale int) error {
+	encoded, err := encodePNG(matrix, scale)
+	if err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(encoded)
+	writer := bufio.NewWriter(w)
+	for first := true; first || len(data) > 0; first = false {
+		chunk := data[:min(kittyChunkSize, len(data))]
+		data = data[len(chunk):]
+		more := 0
+		if len(data) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(writer, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(writer, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	writer.WriteString("\n")
+	return writer.Flush()
+}
+
+// ITerm draws the matrix through the iTerm2 inline images protocol.
+func ITerm(w io.Writer, matrix *lib.BitMatrix, scale int) error {
+	encoded, err := encodePNG(matrix, scale)
+	if err != nil {
+		return err
+	}
+	// size is the length of the PNG itself, not of its base64 encoding
+	data := base64.StdEncoding.EncodeToString(encoded)
+	_, err = fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n", len(encoded), data)
+	return err
+}
+
+// encodePNG returns the PNG of the scaled matrix.
+func encodePNG(matrix *lib.BitMatrix, scale int) ([]byte, error) {
+	img, err := scaled(matrix, scale)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func scaled(matrix *lib.BitMatrix, scale int) (*lib.BitMatrix, error) {
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	output, err := lib.NewBitMatrix(matrix.GetWidth()*scale, matrix.GetHeight()*scale)
+	if err != nil {
+		return nil, err
+	}
+	for y := 0; y < matrix.GetHeight(); y++ {
+		for x := 0; x < matrix.GetWidth(); x++ {
+			if matrix.Get(x, y) {
+				output.SetRegion(x*scale, y*scale, scale, scale)
+			}
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,393 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"golang.org/x/image/draw"
+
+	"github.com/ozgur-yalcin/mfa/lib/qrcode/decoder"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode/encoder"
+)
+
+type ModuleKind int
+
+const (
+	ModuleData ModuleKind = iota
+	ModuleFinder
+	ModuleAlignment
+	ModuleSeparator
+	ModuleTiming
+	ModuleFormat
+)
+
+type Shape string
+
+const (
+	ShapeSquare  Shape = "square"
+	ShapeRounded Shape = "rounded"
+	ShapeDot     Shape = "dot"
+)
+
+const (
+	finderSize    = 7
+	alignmentSize = 5
+
+	// DefaultLogoSize is the width of the logo relative to the code.
+	DefaultLogoSize = 0.2
+
+	// a logo may only use this share of what the error correction can
+	// recover, the rest is left for smudges and bad lighting
+	logoSafety = 0.8
+)
+
+func ParseShape(value string) (Shape, error) {
+	switch shape := Shape(value); shape {
+	case "":
+		return ShapeSquare, nil
+	case ShapeSquare, ShapeRounded, ShapeDot:
+		return shape, nil
+	}
+	return "", errors.New("shape should be square, rounded or dot")
+}
+
+// Style describes how Styled draws a code. Zero colours fall back to
+// Foreground, and a zero Foreground and Background to black on white.
+// Scale is the module size in pixels, when it is zero the largest one that
+// fits into Size is used.
+type Style struct {
+	Level      decoder.ErrorCorrectionLevel
+	Size       int
+	Scale      int
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+	Finder     color.RGBA
+	Alignment  color.RGBA
+	Shape      Shape
+	Logo       image.Image
+	LogoSize   float64
+}
+
+// Classify tells the data modules apart from the function patterns: finder
+// patterns and their separators, alignment and timing patterns, and the format
+// and version information, which also holds the dark module.
+func Classify(code *encoder.QRCode) [][]ModuleKind {
+	matrix := code.GetMatrix()
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	kinds := make([][]ModuleKind, height)
+	for y := range kinds {
+		kinds[y] = make([]ModuleKind, width)
+	}
+	markRect := func(left, top, right, bottom int, kind ModuleKind) {
+		for y := top; y < bottom; y++ {
+			for x := left; x < right; x++ {
+				kinds[y][x] = kind
+			}
+		}
+	}
+	mark := func(left, top, size int, kind ModuleKind) {
+		markRect(left, top, left+size, top+size, kind)
+	}
+	// the format information around the finders, the separators and the
+	// finders are marked in turn over each other
+	markRect(0, 0, finderSize+2, finderSize+2, ModuleFormat)
+	markRect(width-finderSize-1, 0, width, finderSize+2, ModuleFormat)
+	markRect(0, height-finderSize-1, finderSize+2, height, ModuleFormat)
+	mark(0, 0, finderSize+1, ModuleSeparator)
+	mark(width-finderSize-1, 0, finderSize+1, ModuleSeparator)
+	mark(0, height-finderSize-1, finderSize+1, ModuleSeparator)
+	mark(0, 0, finderSize, ModuleFinder)
+	mark(width-finderSize, 0, finderSize, ModuleFinder)
+	mark(0, height-finderSize, finderSize, ModuleFinder)
+	markRect(finderSize+1, finderSize-1, width-finderSize-1, finderSize, ModuleTiming)
+	markRect(finderSize-1, finderSize+1, finderSize, height-finderSize-1, ModuleTiming)
+	if code.GetVersion().GetVersionNumber() >= 7 {
+		markRect(width-finderSize-4, 0, width-finderSize-1, finderSize-1, ModuleFormat)
+		markRect(0, height-finderSize-4, finderSize-1, height-finderSize-1, ModuleFormat)
+	}
+	centers := code.GetVersion().GetAlignmentPatternCenters()
+	for _, cy := range centers {
+		for _, cx := range centers {
+			// alignment patterns are skipped where they would overlap a finder
+			if kinds[cy][cx] == ModuleFinder {
+				continue
+			}
+			mark(cx-alignmentSize/2, cy-alignmentSize/2, alignmentSize, ModuleAlignment)
+		}
+	}
+	return kinds
+}
+
+// Styled encodes contents and draws it with the given style. A logo forces
+// error correction level H and is refused when it would hide more codewords
+// than that level can recover or when it covers a function pattern, such as
+// the alignment pattern in the middle of longer contents.
+func Styled(contents string, style Style) (*image.RGBA, error) {
+	if style.Logo != nil {
+		style.Level = decoder.ErrorCorrectionLevel_H
+	}
+	if style.Margin < 0 {
+		return nil, errors.New("margin cannot be negative")
+	}
+	if style.Foreground == (color.RGBA{}) && style.Background == (color.RGBA{}) {
+		style.Foreground, style.Background = Black, White
+	}
+	if style.Finder == (color.RGBA{}) {
+		style.Finder = style.Foreground
+	}
+	if style.Alignment == (color.RGBA{}) {
+		style.Alignment = style.Foreground
+	}
+	if style.LogoSize <= 0 {
+		style.LogoSize = DefaultLogoSize
+	}
+	code, e := encoder.Encoder_encodeWithoutHint(contents, style.Level)
+	if e != nil {
+		return nil, e
+	}
+	matrix := code.GetMatrix()
+	kinds := Classify(code)
+	dimension := matrix.GetWidth()
+	if style.Scale <= 0 && style.Size > 0 {
+		style.Scale = max(1, style.Size/(dimension+2*style.Margin))
+	} else if style.Scale <= 0 {
+		style.Scale = DefaultScale
+	}
+	var logo image.Rectangle
+	if style.Logo != nil {
+		var err error
+		if logo, err = logoArea(code, kinds, style.LogoSize); err != nil {
+			return nil, err
+		}
+	}
+	size := (dimension + 2*style.Margin) * style.Scale
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(style.Background), image.Point{}, draw.Src)
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < dimension && y < dimension && matrix.Get(x, y) == 1 &&
+			!image.Pt(x, y).In(logo)
+	}
+	for y := 0; y < dimension; y++ {
+		for x := 0; x < dimension; x++ {
+			if !dark(x, y) {
+				continue
+			}
+			cell := modules(image.Rect(x, y, x+1, y+1), style)
+			switch kinds[y][x] {
+			case ModuleFinder:
+				drawModule(img, cell, style.Finder, finderShape(style.Shape), x, y, dark)
+			case ModuleAlignment:
+				drawModule(img, cell, style.Alignment, finderShape(style.Shape), x, y, dark)
+			default:
+				drawModule(img, cell, style.Foreground, style.Shape, x, y, dark)
+			}
+		}
+	}
+	if style.Logo != nil {
+		target := modules(logo, style)
+		// keep half a module of background around the logo
+		inset := style.Scale / 2
+		target = image.Rect(target.Min.X+inset, target.Min.Y+inset, target.Max.X-inset, target.Max.Y-inset)
+		draw.CatmullRom.Scale(img, fit(target, style.Logo.Bounds()), style.Logo, style.Logo.Bounds(), draw.Over, nil)
+	}
+	return img, nil
+}
+
+// modules converts a rectangle of modules into pixels.
+func modules(r image.Rectangle, style Style) image.Rectangle {
+	r = r.Add(image.Pt(style.Margin, style.Margin))
+	return image.Rect(r.Min.X*style.Scale, r.Min.Y*style.Scale, r.Max.X*style.Scale, r.Max.Y*style.Scale)
+}
+
+// finderShape keeps finder and alignment patterns solid, dots would make
+// them hard to detect.
+func finderShape(shape Shape) Shape {
+	if shape == ShapeDot {
+		return ShapeRounded
+	}
+	return shape
+}
+
+// drawModule paints one module. Rounded modules only round the corners
+// whose two neighbours are light, so adjacent modules still join up.
+func drawModule(img *image.RGBA, cell image.Rectangle, c color.RGBA, shape Shape, x, y int, dark func(x, y int) bool) {
+	scale := float64(cell.Dx())
+	radius := scale / 2
+	if shape == ShapeDot {
+		radius = scale * 0.45
+	}
+	rounded := [4]bool{
+		!dark(x-1, y) && !dark(x, y-1),
+		!dark(x+1, y) && !dark(x, y-1),
+		!dark(x-1, y) && !dark(x, y+1),
+		!dark(x+1, y) && !dark(x, y+1),
+	}
+	for py := cell.Min.Y; py < cell.Max.Y; py++ {
+		for px := cell.Min.X; px < cell.Max.X; px++ {
+			fx := float64(px-cell.Min.X) + 0.5
+			fy := float64(py-cell.Min.Y) + 0.5
+			switch shape {
+			case ShapeDot:
+				if math.Hypot(fx-scale/2, fy-scale/2) > radius {
+					continue
+				}
+			case ShapeRounded:
+				corner := -1
+				switch {
+				case fx < radius && fy < radius:
+					corner = 0
+				case fx > scale-radius && fy < radius:
+					corner = 1
+				case fx < radius && fy > scale-radius:
+					corner = 2
+				case fx > scale-radius && fy > scale-radius:
+					corner = 3
+				}
+				if corner >= 0 && rounded[corner] {
+					cx, cy := radius, radius
+					if corner == 1 || corner == 3 {
+						cx = scale - radius
+					}
+					if corner == 2 || corner == 3 {
+						cy = scale - radius
+					}
+					if math.Hypot(fx-cx, fy-cy) > radius {
+						continue
+					}
+				}
+			}
+			img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+// logoArea returns the centred square of modules the logo hides. Every
+// codeword the square touches counts as damaged, and each block of the code
+// has to be able to recover the damaged codewords it holds.
+func logoArea(code *encoder.QRCode, kinds [][]ModuleKind, relative float64) (image.Rectangle, error) {
+	dimension := code.GetMatrix().GetWidth()
+	side := int(math.Round(float64(dimension) * relative))
+	if side%2 != dimension%2 {
+		side++
+	}
+	offset := (dimension - side) / 2
+	area := image.Rect(offset, offset, offset+side, offset+side)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			switch kinds[y][x] {
+			case ModuleData:
+			case ModuleFinder:
+				return area, errors.New("logo would cover a finder pattern, make it smaller")
+			case ModuleAlignment:
+				return area, errors.New("logo would cover an alignment pattern, make it smaller or shorten the contents")
+			default:
+				return area, errors.New("logo would cover a timing pattern or the format information, make it smaller")
+			}
+		}
+	}
+	blocks := codewordBlocks(code)
+	codewords := placement(kinds)
+	ecBlocks := code.GetVersion().GetECBlocksForLevel(code.GetECLevel())
+	// each damaged codeword costs two error correction codewords of its block
+	recoverable := float64(ecBlocks.GetECCodewordsPerBlock()) / 2 * logoSafety
+	damaged := make([]int, ecBlocks.GetNumBlocks())
+	seen := map[int]bool{}
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			codeword := codewords[y][x]
+			if codeword < 0 || codeword >= len(blocks) || seen[codeword] {
+				continue
+			}
+			seen[codeword] = true
+			damaged[blocks[codeword]]++
+		}
+	}
+	for _, count := range damaged {
+		if float64(count) > recoverable {
+			return area, fmt.Errorf("logo would hide %d codewords of a block but error correction level %s can only recover %.0f, make it smaller", count, code.GetECLevel(), recoverable)
+		}
+	}
+	return area, nil
+}
+
+// placement returns the index of the codeword each data module holds,
+// following the placement of the codewords in two module wide columns from
+// the bottom right corner upwards and downwards in turn. Function patterns
+// are -1, and the remainder bits after the last codeword are past its index.
+func placement(kinds [][]ModuleKind) [][]int {
+	dimension := len(kinds)
+	codewords := make([][]int, dimension)
+	for y := range codewords {
+		codewords[y] = make([]int, dimension)
+		for x := range codewords[y] {
+			codewords[y][x] = -1
+		}
+	}
+	bits := 0
+	upwards := true
+	for right := dimension - 1; right > 0; right -= 2 {
+		// the vertical timing pattern takes a whole column
+		if right == finderSize-1 {
+			right--
+		}
+		for count := 0; count < dimension; count++ {
+			y := count
+			if upwards {
+				y = dimension - 1 - count
+			}
+			for x := right; x > right-2; x-- {
+				if kinds[y][x] == ModuleData {
+					codewords[y][x] = bits / 8
+					bits++
+				}
+			}
+		}
+		upwards = !upwards
+	}
+	return codewords
+}
+
+// codewordBlocks returns the error correction block of each codeword in the
+// order they are placed. The data codewords of the blocks are interleaved
+// first, then their error correction codewords, and the blocks with fewer data
+// codewords run out first.
+func codewordBlocks(code *encoder.QRCode) []int {
+	ecBlocks := code.GetVersion().GetECBlocksForLevel(code.GetECLevel())
+	var sizes []int
+	for _, ecb := range ecBlocks.GetECBlocks() {
+		for i := 0; i < ecb.GetCount(); i++ {
+			sizes = append(sizes, ecb.GetDataCodewords())
+		}
+	}
+	var blocks []int
+	for i := 0; i < slices.Max(sizes); i++ {
+		for block, size := range sizes {
+			if i < size {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	for i := 0; i < ecBlocks.GetECCodewordsPerBlock(); i++ {
+		for block := range sizes {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// fit returns the largest rectangle with the aspect ratio of src centred in dst.
+func fit(dst image.Rectangle, src image.Rectangle) image.Rectangle {
+	if src.Dx() == 0 || src.Dy() == 0 {
+		return dst
+	}
+	scale := math.Min(float64(dst.Dx())/float64(src.Dx()), float64(dst.Dy())/float64(src.Dy()))
+	width, height := int(float64(src.Dx())*scale), int(float64(src.Dy())*scale)
+	left := dst.Min.X + (dst.Dx()-width)/2
+	top := dst.Min.Y + (dst.Dy()-height)/2
+	return image.Rect(left, top, left+width, top+height)
+}
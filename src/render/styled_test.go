@@ -0,0 +1,133 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode/decoder"
+	"github.com/ozgur-yalcin/mfa/lib/qrcode/encoder"
+)
+
+const styledURI = "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example"
+
+// logoURI is short enough for a code without an alignment pattern in its
+// middle at error correction level H.
+const logoURI = "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP"
+
+func decode(t *testing.T, img image.Image) string {
+	t.Helper()
+	bitmap, err := lib.NewBinaryBitmap(lib.NewHybridBinarizer(lib.NewLuminanceSourceFromImage(img)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.GetText()
+}
+
+func TestClassify(t *testing.T) {
+	code, err := encoder.Encoder_encodeWithoutHint(styledURI, decoder.ErrorCorrectionLevel_M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[ModuleKind]int{}
+	for _, row := range Classify(code) {
+		for _, kind := range row {
+			counts[kind]++
+		}
+	}
+	if counts[ModuleFinder] != 3*finderSize*finderSize {
+		t.Errorf("got %d finder modules, want %d", counts[ModuleFinder], 3*finderSize*finderSize)
+	}
+	centers := len(code.GetVersion().GetAlignmentPatternCenters())
+	if want := (centers*centers - 3) * alignmentSize * alignmentSize; counts[ModuleAlignment] != want {
+		t.Errorf("got %d alignment modules, want %d", counts[ModuleAlignment], want)
+	}
+	dimension := code.GetMatrix().GetWidth()
+	if want := 2 * (dimension - 2*(finderSize+1)); counts[ModuleTiming]+alignmentSize*(centers-2)*2 != want {
+		t.Errorf("got %d timing modules, want %d", counts[ModuleTiming], want-alignmentSize*(centers-2)*2)
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	for _, contents := range []string{"A", logoURI, styledURI, strings.Repeat(styledURI, 8)} {
+		code, err := encoder.Encoder_encodeWithoutHint(contents, decoder.ErrorCorrectionLevel_H)
+		if err != nil {
+			t.Fatal(err)
+		}
+		version := code.GetVersion()
+		// every codeword is placed, with fewer than eight remainder bits after them
+		counts := make([]int, version.GetTotalCodewords()+1)
+		for _, row := range placement(Classify(code)) {
+			for _, codeword := range row {
+				if codeword >= 0 {
+					counts[codeword]++
+				}
+			}
+		}
+		for codeword, count := range counts[:version.GetTotalCodewords()] {
+			if count != 8 {
+				t.Fatalf("version %s: codeword %d has %d modules, want 8", version, codeword, count)
+			}
+		}
+		if remainder := counts[version.GetTotalCodewords()]; remainder >= 8 {
+			t.Errorf("version %s: got %d remainder bits", version, remainder)
+		}
+		if blocks := codewordBlocks(code); len(blocks) != version.GetTotalCodewords() {
+			t.Errorf("version %s: got %d codewords in blocks, want %d", version, len(blocks), version.GetTotalCodewords())
+		}
+	}
+}
+
+func TestStyled(t *testing.T) {
+	tests := []struct {
+		contents string
+		style    Style
+	}{
+		{styledURI, Style{Shape: ShapeSquare}},
+		{styledURI, Style{Shape: ShapeRounded, Foreground: color.RGBA{0x1A, 0x23, 0x7E, 0xFF}, Background: White}},
+		{styledURI, Style{Shape: ShapeDot, Finder: color.RGBA{0xC6, 0x28, 0x28, 0xFF}}},
+		{logoURI, Style{Shape: ShapeRounded, Logo: fill(image.Rect(0, 0, 40, 20), Black)}},
+		{logoURI, Style{Shape: ShapeSquare, Scale: 8, Logo: fill(image.Rect(0, 0, 32, 32), color.RGBA{0xE0, 0x40, 0x40, 0xFF})}},
+	}
+	for _, tt := range tests {
+		img, err := Styled(tt.contents, tt.style)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.style.Shape, err)
+		}
+		if got := decode(t, img); got != tt.contents {
+			t.Errorf("%s: got %q, want %q", tt.style.Shape, got, tt.contents)
+		}
+	}
+}
+
+func TestStyledLogoTooLarge(t *testing.T) {
+	style := Style{
+		Logo:     fill(image.Rect(0, 0, 10, 10), Black),
+		LogoSize: 0.5,
+	}
+	if _, err := Styled(logoURI, style); err == nil {
+		t.Error("expected an error for a logo larger than the error correction can recover")
+	}
+	// the alignment pattern in the middle of a longer code cannot be hidden
+	style.LogoSize = 0.1
+	if _, err := Styled(styledURI, style); err == nil {
+		t.Error("expected an error for a logo covering an alignment pattern")
+	}
+}
+
+func fill(r image.Rectangle, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
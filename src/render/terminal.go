@@ -0,0 +1,49 @@
+package render
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+const (
+	blockFull  = "█"
+	blockUpper = "▀"
+	blockLower = "▄"
+	blockEmpty = " "
+)
+
+// Terminal draws the matrix with Unicode half blocks, two modules per
+// character cell, so the modules stay square. The matrix is expected to
+// contain its quiet zone, as returned by QRCodeWriter with width and height 0.
+//
+// By default light modules are drawn and dark modules are left empty, which
+// suits terminals with a dark background. Invert draws the dark modules
+// instead, for terminals with a light background.
+func Terminal(w io.Writer, matrix *lib.BitMatrix, invert bool) error {
+	writer := bufio.NewWriter(w)
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	drawn := func(x, y int) bool {
+		// rows below the matrix belong to the quiet zone, which is light
+		dark := y < height && matrix.Get(x, y)
+		return dark == invert
+	}
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			top, bottom := drawn(x, y), drawn(x, y+1)
+			switch {
+			case top && bottom:
+				writer.WriteString(blockFull)
+			case top:
+				writer.WriteString(blockUpper)
+			case bottom:
+				writer.WriteString(blockLower)
+			default:
+				writer.WriteString(blockEmpty)
+			}
+		}
+		writer.WriteString("\n")
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+func TestTerminal(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("X  \n X \n  X\n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		invert bool
+		want   string
+	}{
+		{false, "▄▀█\n██▄\n"},
+		{true, "▀▄ \n  ▀\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := Terminal(&out, matrix, tt.invert); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("invert %v: got %q, want %q", tt.invert, out.String(), tt.want)
+		}
+	}
+}
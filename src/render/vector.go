@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+// VectorOptions controls the vector writers. The matrix is expected to have
+// one module per bit, including its quiet zone, as returned by QRCodeWriter
+// with width and height 0. Size is the width and height of the output in
+// pixels (SVG) or points (EPS, PDF).
+type VectorOptions struct {
+	Size       int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+type run struct {
+	x, y, width int
+}
+
+// runs merges horizontally adjacent dark modules, so each row is drawn
+// with as few rectangles as possible.
+func runs(matrix *lib.BitMatrix) (result []run) {
+	for y := 0; y < matrix.GetHeight(); y++ {
+		for x := 0; x < matrix.GetWidth(); {
+			if !matrix.Get(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < matrix.GetWidth() && matrix.Get(x, y) {
+				x++
+			}
+			result = append(result, run{x: start, y: y, width: x - start})
+		}
+	}
+	return result
+}
+
+func (o VectorOptions) size(matrix *lib.BitMatrix) int {
+	if o.Size > 0 {
+		return o.Size
+	}
+	return matrix.GetWidth() * DefaultScale
+}
+
+func SVG(w io.Writer, matrix *lib.BitMatrix, options VectorOptions) error {
+	writer := bufio.NewWriter(w)
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	size := options.size(matrix)
+	fmt.Fprintf(writer, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(writer, "<svg xmlns=\"http://www.w3.org/2000/svg\" version=\"1.1\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" shape-rendering=\"crispEdges\">\n", size, size, width, height)
+	fmt.Fprintf(writer, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", width, height, hexColor(options.Background))
+	var path strings.Builder
+	for _, r := range runs(matrix) {
+		fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", r.x, r.y, r.width, r.width)
+	}
+	fmt.Fprintf(writer, "<path fill=\"%s\" d=\"%s\"/>\n", hexColor(options.Foreground), path.String())
+	fmt.Fprintf(writer, "</svg>\n")
+	return writer.Flush()
+}
+
+func EPS(w io.Writer, matrix *lib.BitMatrix, options VectorOptions) error {
+	writer := bufio.NewWriter(w)
+	size := options.size(matrix)
+	fmt.Fprintf(writer, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(writer, "%%%%BoundingBox: 0 0 %d %d\n", size, size)
+	fmt.Fprintf(writer, "%%%%Creator: mfa\n")
+	fmt.Fprintf(writer, "%%%%EndComments\n")
+	fmt.Fprintf(writer, "gsave\n")
+	writer.WriteString(drawing(matrix, options, size, "setrgbcolor", "rectfill"))
+	fmt.Fprintf(writer, "grestore\n")
+	fmt.Fprintf(writer, "showpage\n")
+	fmt.Fprintf(writer, "%%%%EOF\n")
+	return writer.Flush()
+}
+
+// PDF writes a minimal single page PDF with the page sized to the code.
+func PDF(w io.Writer, matrix *lib.BitMatrix, options VectorOptions) error {
+	size := options.size(matrix)
+	content := drawing(matrix, options, size, "rg", "re f")
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents 4 0 R /Resources << >> >>", size, size),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// drawing returns the PostScript or PDF operators that paint the background
+// and the dark runs. Both languages put the origin at the bottom left, so
+// rows are flipped.
+func drawing(matrix *lib.BitMatrix, options VectorOptions, size int, setColor string, fill string) string {
+	var b strings.Builder
+	width, height := matrix.GetWidth(), matrix.GetHeight()
+	scale := float64(size) / float64(width)
+	fmt.Fprintf(&b, "%s %s\n", rgb(options.Background), setColor)
+	fmt.Fprintf(&b, "0 0 %d %d %s\n", size, size, fill)
+	fmt.Fprintf(&b, "%s %s\n", rgb(options.Foreground), setColor)
+	for _, r := range runs(matrix) {
+		fmt.Fprintf(&b, "%s %s %s %s %s\n",
+			number(float64(r.x)*scale), number(float64(height-r.y-1)*scale),
+			number(float64(r.width)*scale), number(scale), fill)
+	}
+	return b.String()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func rgb(c color.RGBA) string {
+	return fmt.Sprintf("%s %s %s", number(float64(c.R)/255), number(float64(c.G)/255), number(float64(c.B)/255))
+}
+
+func number(value float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.4f", value), "0")
+	return strings.TrimSuffix(s, ".")
+}
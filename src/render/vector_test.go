@@ -0,0 +1,73 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ozgur-yalcin/mfa/lib"
+)
+
+func TestSVG(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("XX \n XX\n   \n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	options := VectorOptions{Size: 30, Foreground: Black, Background: White}
+	if err := SVG(&out, matrix, options); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<path fill="#000000" d="M0 0h2v1h-2zM1 1h2v1h-2z"/>`; !strings.Contains(out.String(), want) {
+		t.Errorf("got %s, want it to contain %s", out.String(), want)
+	}
+}
+
+func TestEPS(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("XX \n XX\n   \n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	options := VectorOptions{Size: 30, Foreground: Black, Background: White}
+	if err := EPS(&out, matrix, options); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"%%BoundingBox: 0 0 30 30\n", "0 20 20 10 rectfill\n", "10 10 20 10 rectfill\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got %s, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	matrix, err := lib.ParseStringToBitMatrix("XX \n XX\n   \n", "X", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	options := VectorOptions{Size: 30, Foreground: Black, Background: White}
+	if err := PDF(&out, matrix, options); err != nil {
+		t.Fatal(err)
+	}
+	pdf := out.String()
+	for _, want := range []string{"/MediaBox [0 0 30 30]", "0 20 20 10 re f\n"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("got %s, want it to contain %q", pdf, want)
+		}
+	}
+	// every xref entry should point at the start of its object
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1)
+	if len(entries) != 4 {
+		t.Fatalf("got %d xref entries, want 4", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import "unicode/utf8"
+
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyInterrupt
+	KeyEOF
+	KeyClear
+)
+
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// ParseKeys decodes the bytes read from a terminal in raw mode. A lone escape
+// at the end of the input is the escape key, escape sequences that are not
+// known are dropped.
+func ParseKeys(b []byte) (keys []Key) {
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				keys = append(keys, Key{Code: KeyEscape})
+				return
+			}
+			if b[1] != '[' && b[1] != 'O' {
+				// alt with a key, not used
+				_, size := utf8.DecodeRune(b[1:])
+				b = b[1+size:]
+				continue
+			}
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end == len(b) {
+				return
+			}
+			if key, ok := sequence(string(b[2:end]), b[end]); ok {
+				keys = append(keys, key)
+			}
+			b = b[end+1:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyInterrupt})
+		case c == 0x04:
+			keys = append(keys, Key{Code: KeyEOF})
+		case c == 0x15:
+			keys = append(keys, Key{Code: KeyClear})
+		case c == 0x10:
+			keys = append(keys, Key{Code: KeyUp})
+		case c == 0x0e:
+			keys = append(keys, Key{Code: KeyDown})
+		case c >= 0x20:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return
+}
+
+func sequence(params string, final byte) (Key, bool) {
+	switch final {
+	case 'A':
+		return Key{Code: KeyUp}, true
+	case 'B':
+		return Key{Code: KeyDown}, true
+	case 'H':
+		return Key{Code: KeyHome}, true
+	case 'F':
+		return Key{Code: KeyEnd}, true
+	case '~':
+		switch params {
+		case "1", "7":
+			return Key{Code: KeyHome}, true
+		case "4", "8":
+			return Key{Code: KeyEnd}, true
+		case "5":
+			return Key{Code: KeyPageUp}, true
+		case "6":
+			return Key{Code: KeyPageDown}, true
+		}
+	}
+	return Key{}, false
+}
@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := map[string][]Key{
+		"ab":             {{Code: KeyRune, Rune: 'a'}, {Code: KeyRune, Rune: 'b'}},
+		"ç":              {{Code: KeyRune, Rune: 'ç'}},
+		"\x1b[A\x1bOB":   {{Code: KeyUp}, {Code: KeyDown}},
+		"\x1b[5~\x1b[6~": {{Code: KeyPageUp}, {Code: KeyPageDown}},
+		"\x1b[1;5A":      {{Code: KeyUp}},
+		"\x1b[2~x":       {{Code: KeyRune, Rune: 'x'}},
+		"\x1b":           {{Code: KeyEscape}},
+		"\x1bx":          nil,
+		"\r\x7f\x03":     {{Code: KeyEnter}, {Code: KeyBackspace}, {Code: KeyInterrupt}},
+		"\x15\x04":       {{Code: KeyClear}, {Code: KeyEOF}},
+	}
+	for input, want := range tests {
+		if got := ParseKeys([]byte(input)); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseKeys(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	EnterAltScreen = "\x1b[?1049h"
+	ExitAltScreen  = "\x1b[?1049l"
+	HideCursor     = "\x1b[?25l"
+	ShowCursor     = "\x1b[?25h"
+	Home           = "\x1b[H"
+	ClearLine      = "\x1b[K"
+	ClearBelow     = "\x1b[J"
+	Reverse        = "\x1b[7m"
+	Bold           = "\x1b[1m"
+	Dim            = "\x1b[2m"
+	Red            = "\x1b[31m"
+	Yellow         = "\x1b[33m"
+	Green          = "\x1b[32m"
+	Reset          = "\x1b[0m"
+)
+
+// Bar draws the remaining part of a period as a bar width cells wide.
+func Bar(remaining int64, period int64, width int) string {
+	if period <= 0 || width <= 0 {
+		return ""
+	}
+	remaining = max(0, min(remaining, period))
+	filled := int((remaining*int64(width) + period - 1) / period)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// Truncate cuts s to width runes, escape sequences are not counted.
+func Truncate(s string, width int) string {
+	var b strings.Builder
+	visible := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			end := strings.IndexFunc(s[i+1:], func(r rune) bool { return r >= 0x40 && r <= 0x7e && r != '[' })
+			if end < 0 {
+				break
+			}
+			b.WriteString(s[i : i+end+2])
+			i += end + 2
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if visible < width {
+			b.WriteRune(r)
+		}
+		visible++
+		i += size
+	}
+	return b.String()
+}
+
+// Pad fills s with spaces up to width runes.
+func Pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
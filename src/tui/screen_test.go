@@ -0,0 +1,31 @@
+package tui
+
+import "testing"
+
+func TestBar(t *testing.T) {
+	tests := []struct {
+		remaining, period int64
+		width             int
+		want              string
+	}{
+		{30, 30, 4, "████"},
+		{15, 30, 4, "██░░"},
+		{1, 30, 4, "█░░░"},
+		{0, 30, 4, "░░░░"},
+		{90, 60, 2, "██"},
+	}
+	for _, test := range tests {
+		if got := Bar(test.remaining, test.period, test.width); got != test.want {
+			t.Errorf("Bar(%d, %d, %d) = %q, want %q", test.remaining, test.period, test.width, got, test.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got, want := Truncate(Bold+"héllo"+Reset+" world", 3), Bold+"hél"+Reset; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := Truncate("abc", 10); got != "abc" {
+		t.Errorf("got %q, want %q", got, "abc")
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+var ErrNotTerminal = errors.New("not a terminal")
+
+type State struct{}
+
+func IsTerminal(fd int) bool {
+	return false
+}
+
+func MakeRaw(fd int) (*State, error) {
+	return nil, ErrNotTerminal
+}
+
+func Restore(fd int, state *State) error {
+	return ErrNotTerminal
+}
+
+func NotifyResize(c chan<- os.Signal) {}
+
+func Size(fd int) (width int, height int, err error) {
+	return 0, 0, ErrNotTerminal
+}